@@ -0,0 +1,33 @@
+package communication
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Tells the slaves that an experiment is running and where its requests are.
+// The problem describes in JSON the problem of the experiment to the slaves
+// not written in Go, which cannot decode its context.
+type Announcement struct {
+	ExperimentId string          `json:"experimentId"`
+	RequestQueue string          `json:"requestQueue"`
+	Problem      json.RawMessage `json:"problem,omitempty"`
+}
+
+func (announcement *Announcement) Encode() ([]byte, error) {
+	data, err := json.Marshal(announcement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode announcement: %v", err)
+	}
+	return data, nil
+}
+
+func (announcement *Announcement) Decode(data []byte) error {
+	if err := json.Unmarshal(data, announcement); err != nil {
+		return fmt.Errorf("failed to decode announcement: %v", err)
+	}
+	if announcement.ExperimentId == "" || announcement.RequestQueue == "" {
+		return fmt.Errorf("failed to decode announcement: missing experiment id or request queue")
+	}
+	return nil
+}
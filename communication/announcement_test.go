@@ -0,0 +1,34 @@
+package communication
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnnouncementRoundTrip(t *testing.T) {
+	announcement := &Announcement{
+		ExperimentId: "abc",
+		RequestQueue: RequestQueueName("abc"),
+		Problem:      []byte(`{"Name":"sphere"}`),
+	}
+	data, err := announcement.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := new(Announcement)
+	if err := decoded.Decode(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, announcement) {
+		t.Errorf("expected %v, got %v", announcement, decoded)
+	}
+}
+
+func TestAnnouncementDecodeMalformed(t *testing.T) {
+	for _, data := range []string{"", "{", `{"experimentId": "abc"}`} {
+		if err := new(Announcement).Decode([]byte(data)); err == nil {
+			t.Errorf("expected an error decoding %q", data)
+		}
+	}
+}
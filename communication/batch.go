@@ -0,0 +1,88 @@
+package communication
+
+import (
+	"math"
+	"time"
+)
+
+// Default ratio between the latency of a message and the evaluation time of
+// its batch targeted by the adaptive batch size.
+const DefaultOverheadRatio = 0.1
+
+// Chooses the number of individuals packed in a message. When adaptive, the
+// size grows until the latency of a message, measured as its round trip minus
+// the evaluation time reported by the slave, is a small fraction of the
+// evaluation time of its batch.
+type BatchSizer struct {
+	Size          int
+	MaxSize       int
+	Adaptive      bool
+	OverheadRatio float64
+
+	// Observations since the last adaptation. The latency is the minimum
+	// one, since the other messages also waited in the queue.
+	latency        time.Duration
+	evaluationTime time.Duration
+	evaluated      int
+}
+
+// Creates a batch sizer starting from size, and never exceeding maxSize if positive.
+func NewBatchSizer(size, maxSize int, adaptive bool) *BatchSizer {
+	sizer := &BatchSizer{
+		Size:          size,
+		MaxSize:       maxSize,
+		Adaptive:      adaptive,
+		OverheadRatio: DefaultOverheadRatio,
+		latency:       -1,
+	}
+	sizer.Size = sizer.clamp(size)
+	return sizer
+}
+
+func (sizer *BatchSizer) clamp(size int) int {
+	if sizer.MaxSize > 0 && size > sizer.MaxSize {
+		size = sizer.MaxSize
+	}
+	if size < 1 {
+		size = 1
+	}
+	return size
+}
+
+// Records the round trip of a batch and the time its evaluation took on the slave.
+func (sizer *BatchSizer) Observe(batchSize int, roundTrip, evaluationTime time.Duration) {
+	latency := roundTrip - evaluationTime
+	if latency < 0 {
+		latency = 0
+	}
+	if sizer.latency < 0 || latency < sizer.latency {
+		sizer.latency = latency
+	}
+	sizer.evaluationTime += evaluationTime
+	sizer.evaluated += batchSize
+}
+
+// Updates the batch size from the observations since the last update.
+func (sizer *BatchSizer) Adapt() {
+	defer func() {
+		sizer.latency = -1
+		sizer.evaluationTime = 0
+		sizer.evaluated = 0
+	}()
+
+	if !sizer.Adaptive || sizer.evaluated == 0 {
+		return
+	}
+
+	evaluationTime := float64(sizer.evaluationTime) / float64(sizer.evaluated)
+	if evaluationTime <= 0 {
+		sizer.Size = sizer.clamp(math.MaxInt32)
+		return
+	}
+
+	size := math.Ceil(float64(sizer.latency) / (sizer.OverheadRatio * evaluationTime))
+	if size > math.MaxInt32 {
+		size = math.MaxInt32
+	}
+	sizer.Size = sizer.clamp(int(size))
+}
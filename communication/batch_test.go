@@ -0,0 +1,47 @@
+package communication
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBatchSizerFixed(t *testing.T) {
+	sizer := NewBatchSizer(4, 10, false)
+	sizer.Observe(4, 10*time.Millisecond, time.Millisecond)
+	sizer.Adapt()
+	if sizer.Size != 4 {
+		t.Errorf("expected 4, got %v", sizer.Size)
+	}
+}
+
+func TestBatchSizerAdaptive(t *testing.T) {
+	sizer := NewBatchSizer(1, 100, true)
+
+	// 2 ms of latency for 1 ms of evaluation per individual.
+	sizer.Observe(1, 3*time.Millisecond, time.Millisecond)
+	sizer.Observe(1, 5*time.Millisecond, time.Millisecond)
+	sizer.Adapt()
+	if sizer.Size != 20 {
+		t.Errorf("expected 20, got %v", sizer.Size)
+	}
+
+	// The size is bounded by the maximum.
+	sizer.Observe(20, 30*time.Millisecond, 2*time.Millisecond)
+	sizer.Adapt()
+	if sizer.Size != 100 {
+		t.Errorf("expected 100, got %v", sizer.Size)
+	}
+
+	// Slow evaluations shrink the batches.
+	sizer.Observe(100, 2100*time.Millisecond, 2*time.Second)
+	sizer.Adapt()
+	if sizer.Size != 50 {
+		t.Errorf("expected 50, got %v", sizer.Size)
+	}
+
+	// Without observations the size is kept.
+	sizer.Adapt()
+	if sizer.Size != 50 {
+		t.Errorf("expected 50, got %v", sizer.Size)
+	}
+}
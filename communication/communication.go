@@ -7,111 +7,516 @@ import (
 	"github.com/streadway/amqp"
 
 	"github.com/pasqualesalza/amqpga/config"
-	"github.com/pasqualesalza/amqpga/util"
 )
 
-// Creates the request queue.
-func CreateRequestQueue(channel *amqp.Channel) *amqp.Queue {
-	requestQueue, err := channel.QueueDeclare(
-		config.RequestQueueName, // name
+// An error of the broker, described by the operation that failed.
+type Error struct {
+	Description string
+	Cause       error
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("%v: %v", err.Description, err.Cause)
+}
+
+func wrapError(cause error, format string, arguments ...interface{}) error {
+	return &Error{
+		Description: fmt.Sprintf(format, arguments...),
+		Cause:       cause,
+	}
+}
+
+// Creates the dead letter exchange and its queue, receiving the rejected messages.
+func CreateDeadLetterQueue(channel *amqp.Channel) (*amqp.Queue, error) {
+	err := channel.ExchangeDeclare(
+		config.DeadLetterExchangeName, // name
+		"fanout", // kind
+		true,     // durable
+		false,    // autoDelete
+		false,    // internal
+		false,    // noWait
+		nil,      // arguments
+	)
+	if err != nil {
+		return nil, wrapError(err, "failed to declare the dead letter exchange")
+	}
+
+	deadLetterQueue, err := channel.QueueDeclare(
+		config.DeadLetterQueueName, // name
 		true,  // durable
-		true,  // autoDelete
+		false, // autoDelete
 		false, // exclusive
 		false, // noWait
 		nil,   // arguments
 	)
+	if err != nil {
+		return nil, wrapError(err, "failed to declare the dead letter queue")
+	}
+
+	err = channel.QueueBind(
+		deadLetterQueue.Name,          // name
+		"",                            // key
+		config.DeadLetterExchangeName, // exchange
+		false, // noWait
+		nil,   // arguments
+	)
+	if err != nil {
+		return nil, wrapError(err, "failed to bind the dead letter queue")
+	}
 
-	util.FailOnError(err, "Failed to declare the request queue")
 	log.WithFields(log.Fields{
-		"queue": config.RequestQueueName,
-	}).Info("Request queue created")
+		"queue": config.DeadLetterQueueName,
+	}).Info("Dead letter queue created")
+
+	return &deadLetterQueue, nil
+}
 
-	return &requestQueue
+// Returns the name of the request queue of an experiment.
+func RequestQueueName(experimentId string) string {
+	return fmt.Sprintf("%v_%v", config.RequestQueueName, experimentId)
 }
 
-// Creates the response queue.
-func CreateResponseQueue(channel *amqp.Channel) *amqp.Queue {
-	responseQueue, err := channel.QueueDeclare(
-		config.ResponseQueueName, // name
+// Returns the name of the queue receiving the responses of a master.
+func ReplyQueueName(experimentId string, nodeId string) string {
+	return fmt.Sprintf("%v_%v_%v", config.ResponseQueueName, experimentId, nodeId)
+}
+
+// Creates the request queue of an experiment.
+func CreateRequestQueue(channel *amqp.Channel, experimentId string) (*amqp.Queue, error) {
+	requestQueue, err := channel.QueueDeclare(
+		RequestQueueName(experimentId), // name
 		true,  // durable
 		true,  // autoDelete
 		false, // exclusive
 		false, // noWait
-		nil,   // arguments
+		amqp.Table{"x-dead-letter-exchange": config.DeadLetterExchangeName}, // arguments
 	)
+	if err != nil {
+		return nil, wrapError(err, "failed to declare the request queue")
+	}
 
-	util.FailOnError(err, "Failed to declare the response queue")
 	log.WithFields(log.Fields{
-		"queue": config.ResponseQueueName,
+		"queue": requestQueue.Name,
 	}).Info("Request queue created")
 
-	return &responseQueue
+	return &requestQueue, nil
+}
+
+// Creates the queue receiving the responses of a master, deleted with its
+// connection.
+func CreateReplyQueue(channel *amqp.Channel, experimentId string, nodeId string) (*amqp.Queue, error) {
+	replyQueue, err := channel.QueueDeclare(
+		ReplyQueueName(experimentId, nodeId), // name
+		false, // durable
+		true,  // autoDelete
+		true,  // exclusive
+		false, // noWait
+		amqp.Table{"x-dead-letter-exchange": config.DeadLetterExchangeName}, // arguments
+	)
+	if err != nil {
+		return nil, wrapError(err, "failed to declare the reply queue")
+	}
+
+	log.WithFields(log.Fields{
+		"queue": replyQueue.Name,
+	}).Info("Reply queue created")
+
+	return &replyQueue, nil
+}
+
+// Deletes the request queue of a finished experiment, so that its consumers
+// are cancelled.
+func DeleteRequestQueue(channel *amqp.Channel, experimentId string) error {
+	_, err := channel.QueueDelete(
+		RequestQueueName(experimentId), // name
+		false, // ifUnused
+		false, // ifEmpty
+		false, // noWait
+	)
+	if err != nil {
+		return wrapError(err, "failed to delete the request queue")
+	}
+	return nil
+}
+
+// Creates the fanout exchange announcing the running experiments to the slaves.
+func CreateAnnouncementExchange(channel *amqp.Channel) error {
+	err := channel.ExchangeDeclare(
+		config.AnnouncementExchangeName, // name
+		"fanout", // kind
+		true,     // durable
+		false,    // autoDelete
+		false,    // internal
+		false,    // noWait
+		nil,      // arguments
+	)
+	if err != nil {
+		return wrapError(err, "failed to declare the announcement exchange")
+	}
+
+	log.WithFields(log.Fields{
+		"exchange": config.AnnouncementExchangeName,
+	}).Info("Announcement exchange created")
+
+	return nil
+}
+
+// Returns the name of the queue receiving the announcements for a slave.
+func AnnouncementQueueName(nodeId string) string {
+	return fmt.Sprintf("%v_%v", config.AnnouncementExchangeName, nodeId)
+}
+
+// Creates the queue receiving the announcements for a slave.
+func CreateAnnouncementQueue(channel *amqp.Channel, nodeId string) (*amqp.Queue, error) {
+	announcementQueue, err := channel.QueueDeclare(
+		AnnouncementQueueName(nodeId), // name
+		false, // durable
+		true,  // autoDelete
+		true,  // exclusive
+		false, // noWait
+		nil,   // arguments
+	)
+	if err != nil {
+		return nil, wrapError(err, "failed to declare the announcement queue")
+	}
+
+	err = channel.QueueBind(
+		announcementQueue.Name,          // name
+		"",                              // key
+		config.AnnouncementExchangeName, // exchange
+		false, // noWait
+		nil,   // arguments
+	)
+	if err != nil {
+		return nil, wrapError(err, "failed to bind the announcement queue")
+	}
+
+	log.WithFields(log.Fields{
+		"queue": announcementQueue.Name,
+	}).Info("Announcement queue created")
+
+	return &announcementQueue, nil
+}
+
+// Creates the topic exchange carrying the migrants between the islands.
+func CreateMigrationExchange(channel *amqp.Channel) error {
+	err := channel.ExchangeDeclare(
+		config.MigrationExchangeName, // name
+		"topic", // kind
+		true,    // durable
+		true,    // autoDelete
+		false,   // internal
+		false,   // noWait
+		nil,     // arguments
+	)
+	if err != nil {
+		return wrapError(err, "failed to declare the migration exchange")
+	}
+
+	log.WithFields(log.Fields{
+		"exchange": config.MigrationExchangeName,
+	}).Info("Migration exchange created")
+
+	return nil
+}
+
+// Returns the routing key of the migrants sent to an island of an experiment.
+func IslandRoutingKey(experimentId string, island int) string {
+	return fmt.Sprintf("island.%v.%v", experimentId, island)
+}
+
+// Returns the name of the queue receiving the immigrants of an island of an
+// experiment.
+func MigrationQueueName(experimentId string, island int) string {
+	return fmt.Sprintf("%v_%v_%v", config.MigrationExchangeName, experimentId, island)
+}
+
+// Creates the queue receiving the immigrants of an island of an experiment.
+func CreateMigrationQueue(channel *amqp.Channel, experimentId string, island int) (*amqp.Queue, error) {
+	migrationQueue, err := channel.QueueDeclare(
+		MigrationQueueName(experimentId, island), // name
+		false, // durable
+		true,  // autoDelete
+		true,  // exclusive
+		false, // noWait
+		nil,   // arguments
+	)
+	if err != nil {
+		return nil, wrapError(err, "failed to declare the migration queue")
+	}
+
+	err = channel.QueueBind(
+		migrationQueue.Name,                    // name
+		IslandRoutingKey(experimentId, island), // key
+		config.MigrationExchangeName,           // exchange
+		false, // noWait
+		nil,   // arguments
+	)
+	if err != nil {
+		return nil, wrapError(err, "failed to bind the migration queue")
+	}
+
+	log.WithFields(log.Fields{
+		"queue":      migrationQueue.Name,
+		"experiment": experimentId,
+		"island":     island,
+	}).Info("Migration queue created")
+
+	return &migrationQueue, nil
+}
+
+// Creates the topic exchange carrying the problem contexts from the masters to
+// the slaves and the requests of the contexts from the slaves to the masters.
+func CreateContextExchange(channel *amqp.Channel) error {
+	err := channel.ExchangeDeclare(
+		config.ContextExchangeName, // name
+		"topic", // kind
+		true,    // durable
+		false,   // autoDelete
+		false,   // internal
+		false,   // noWait
+		nil,     // arguments
+	)
+	if err != nil {
+		return wrapError(err, "failed to declare the context exchange")
+	}
+
+	log.WithFields(log.Fields{
+		"exchange": config.ContextExchangeName,
+	}).Info("Context exchange created")
+
+	return nil
+}
+
+// Returns the routing key of the context of an experiment, or of every
+// experiment with the * wildcard.
+func ContextRoutingKey(experimentId string) string {
+	return fmt.Sprintf("context.%v", experimentId)
+}
+
+// Returns the routing key of the requests of the context of an experiment.
+func ContextRequestRoutingKey(experimentId string) string {
+	return fmt.Sprintf("request.%v", experimentId)
+}
+
+// Returns the name of the queue receiving the contexts or their requests for a node.
+func ContextQueueName(nodeId string) string {
+	return fmt.Sprintf("%v_%v", config.ContextExchangeName, nodeId)
+}
+
+// Creates the queue receiving the context messages routed with a key.
+func CreateContextQueue(channel *amqp.Channel, nodeId string, key string) (*amqp.Queue, error) {
+	contextQueue, err := channel.QueueDeclare(
+		ContextQueueName(nodeId), // name
+		false, // durable
+		true,  // autoDelete
+		true,  // exclusive
+		false, // noWait
+		nil,   // arguments
+	)
+	if err != nil {
+		return nil, wrapError(err, "failed to declare the context queue")
+	}
+
+	err = channel.QueueBind(
+		contextQueue.Name,          // name
+		key,                        // key
+		config.ContextExchangeName, // exchange
+		false, // noWait
+		nil,   // arguments
+	)
+	if err != nil {
+		return nil, wrapError(err, "failed to bind the context queue")
+	}
+
+	log.WithFields(log.Fields{
+		"queue": contextQueue.Name,
+		"key":   key,
+	}).Info("Context queue created")
+
+	return &contextQueue, nil
 }
 
 // Connects to the server.
-func Connect(host string) *amqp.Connection {
+func Connect(host string) (*amqp.Connection, error) {
 	connection, err := amqp.Dial(host)
+	if err != nil {
+		return nil, wrapError(err, "failed to connect to RabbitMQ")
+	}
 
-	util.FailOnError(err, "Failed to connect to RabbitMQ.")
 	log.WithFields(log.Fields{
 		"rabbitMQHost": host,
 	}).Info("Connected to RabbitMQ")
 
-	return connection
+	return connection, nil
 }
 
 // Opens a channel.
-func OpenChannel(connection *amqp.Connection) *amqp.Channel {
+func OpenChannel(connection *amqp.Connection) (*amqp.Channel, error) {
 	channel, err := connection.Channel()
+	if err != nil {
+		return nil, wrapError(err, "failed to open a channel")
+	}
 
-	util.FailOnError(err, "Failed to open a channel")
 	log.Info("Channel opened")
 
-	return channel
+	return channel, nil
 }
 
-// Sets the dispatcher to be fair.
-func SetFairDispatch(channel *amqp.Channel) {
+// Sets the dispatcher to be fair, delivering to each consumer of the channel
+// up to prefetchCount messages not acknowledged yet.
+func SetFairDispatch(channel *amqp.Channel, prefetchCount int) error {
 	err := channel.Qos(
-		1,     // prefetchCount
-		0,     // prefetchSize
-		false, // global
+		prefetchCount, // prefetchCount
+		0,             // prefetchSize
+		false,         // global
 	)
+	if err != nil {
+		return wrapError(err, "failed to set the fair dispatch")
+	}
 
-	util.FailOnError(err, "Failed to set the fair dispatch")
 	log.Info("Fair dispatch set")
+
+	return nil
 }
 
 // Consumes a queue.
-func ConsumeQueue(channel *amqp.Channel, queue *amqp.Queue) <-chan amqp.Delivery {
+func ConsumeQueue(channel *amqp.Channel, queue *amqp.Queue) (<-chan amqp.Delivery, error) {
+	return ConsumeQueueAs(channel, queue, "")
+}
+
+// Consumes a queue with a consumer tag, needed to cancel the consumer.
+func ConsumeQueueAs(channel *amqp.Channel, queue *amqp.Queue, consumer string) (<-chan amqp.Delivery, error) {
 	messages, err := channel.Consume(
 		queue.Name, // queue
-		"",         // consumer
+		consumer,   // consumer
 		false,      // autoAck
 		false,      // exclusive
 		false,      // noLocal
 		false,      // noWait
 		nil,        // args
 	)
+	if err != nil {
+		return nil, wrapError(err, "failed to register as consumer to the %v queue", queue.Name)
+	}
 
-	util.FailOnError(err, fmt.Sprintf("Failed to register as consumer to the %v queue", queue.Name))
 	log.WithFields(log.Fields{
 		"queue": queue.Name,
 	}).Infof("Registered as consumer to the %v queue", queue.Name)
 
-	return messages
+	return messages, nil
 }
 
 // Sends a message to the queue.
-func PublishMessage(data []byte, channel *amqp.Channel, queue *amqp.Queue) {
+func PublishMessage(data []byte, channel *amqp.Channel, queue *amqp.Queue) error {
+	return PublishBatch(data, BatchProperties{ContentType: "binary/gob"}, channel, queue)
+}
+
+// The properties of a message with a batch of individuals. The content type and
+// encoding name the codec of the batch, the correlation id pairs a response
+// with its request, and the reply to is the queue of the response.
+type BatchProperties struct {
+	ContentType     string
+	ContentEncoding string
+	CorrelationId   string
+	ReplyTo         string
+	Headers         amqp.Table
+}
+
+// Sends a message with a batch of individuals to the queue.
+func PublishBatch(data []byte, properties BatchProperties, channel *amqp.Channel, queue *amqp.Queue) error {
 	// Publishes the message.
 	err := channel.Publish(
 		"",         // exchange
 		queue.Name, // key
 		false,      // mandatory
 		false,      // immediate
+		amqp.Publishing{
+			ContentType:     properties.ContentType,
+			ContentEncoding: properties.ContentEncoding,
+			CorrelationId:   properties.CorrelationId,
+			ReplyTo:         properties.ReplyTo,
+			Headers:         properties.Headers,
+			Body:            data})
+	if err != nil {
+		return wrapError(err, "failed to publish a message on the %v queue", queue.Name)
+	}
+	return nil
+}
+
+// Sends a migrant to an island of an experiment.
+func PublishMigrant(data []byte, channel *amqp.Channel, experimentId string, island int) error {
+	err := channel.Publish(
+		config.MigrationExchangeName,           // exchange
+		IslandRoutingKey(experimentId, island), // key
+		false, // mandatory
+		false, // immediate
+		amqp.Publishing{
+			ContentType: "binary/gob",
+			Body:        data})
+	if err != nil {
+		return wrapError(err, "failed to publish a migrant to the island %v", island)
+	}
+	return nil
+}
+
+// Announces an experiment to the slaves.
+func PublishAnnouncement(data []byte, channel *amqp.Channel) error {
+	err := channel.Publish(
+		config.AnnouncementExchangeName, // exchange
+		"",    // key
+		false, // mandatory
+		false, // immediate
+		amqp.Publishing{
+			ContentType: "application/json",
+			Body:        data})
+	if err != nil {
+		return wrapError(err, "failed to publish an announcement")
+	}
+	return nil
+}
+
+// Sends the context of an experiment to the slaves, tagged with its hash.
+func PublishContext(data []byte, hash string, channel *amqp.Channel, experimentId string) error {
+	err := channel.Publish(
+		config.ContextExchangeName,      // exchange
+		ContextRoutingKey(experimentId), // key
+		false, // mandatory
+		false, // immediate
 		amqp.Publishing{
 			ContentType: "binary/gob",
+			Headers:     amqp.Table{config.ContextHashHeader: hash},
 			Body:        data})
-	util.FailOnError(err, fmt.Sprintf("Failed to publish a message on the %v queue", queue.Name))
+	if err != nil {
+		return wrapError(err, "failed to publish the context of the experiment %v", experimentId)
+	}
+	return nil
+}
+
+// Asks the master of an experiment to send its context again.
+func PublishContextRequest(channel *amqp.Channel, experimentId string) error {
+	err := channel.Publish(
+		config.ContextExchangeName,             // exchange
+		ContextRequestRoutingKey(experimentId), // key
+		false, // mandatory
+		false, // immediate
+		amqp.Publishing{})
+	if err != nil {
+		return wrapError(err, "failed to request the context of the experiment %v", experimentId)
+	}
+	return nil
+}
+
+// Rejects a message that cannot be processed, so that the broker moves it to
+// the dead letter queue instead of delivering it again.
+func DeadLetter(message amqp.Delivery, reason error) error {
+	log.WithFields(log.Fields{
+		"error":         reason,
+		"correlationId": message.CorrelationId,
+	}).Warn("Rejected a message to the dead letter queue")
+
+	if err := message.Nack(false, false); err != nil {
+		return wrapError(err, "failed to reject a message")
+	}
+	return nil
 }
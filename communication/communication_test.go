@@ -0,0 +1,66 @@
+package communication
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/streadway/amqp"
+)
+
+// Records the acknowledgements of the deliveries.
+type fakeAcknowledger struct {
+	acks     int
+	nacks    int
+	requeued bool
+}
+
+func (acknowledger *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	acknowledger.acks++
+	return nil
+}
+
+func (acknowledger *fakeAcknowledger) Nack(tag uint64, multiple bool, requeue bool) error {
+	acknowledger.nacks++
+	acknowledger.requeued = requeue
+	return nil
+}
+
+func (acknowledger *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	return acknowledger.Nack(tag, false, requeue)
+}
+
+func TestDeadLetter(t *testing.T) {
+	acknowledger := new(fakeAcknowledger)
+	message := amqp.Delivery{Acknowledger: acknowledger, Body: []byte("malformed")}
+
+	if err := DeadLetter(message, errors.New("malformed message")); err != nil {
+		t.Fatal(err)
+	}
+	if acknowledger.nacks != 1 || acknowledger.acks != 0 {
+		t.Errorf("expected a single nack, got %v nacks and %v acks", acknowledger.nacks, acknowledger.acks)
+	}
+	if acknowledger.requeued {
+		t.Error("the message must not be requeued")
+	}
+}
+
+func TestIslandRoutingKey(t *testing.T) {
+	if key := IslandRoutingKey("abc", 3); key != "island.abc.3" {
+		t.Errorf("expected island.abc.3, got %v", key)
+	}
+}
+
+func TestQueueNames(t *testing.T) {
+	if name := RequestQueueName("abc"); name != "amqpga_request_abc" {
+		t.Errorf("expected amqpga_request_abc, got %v", name)
+	}
+	if name := ReplyQueueName("abc", "node"); name != "amqpga_response_abc_node" {
+		t.Errorf("expected amqpga_response_abc_node, got %v", name)
+	}
+	if name := MigrationQueueName("abc", 3); name != "amqpga_migration_abc_3" {
+		t.Errorf("expected amqpga_migration_abc_3, got %v", name)
+	}
+	if name := AnnouncementQueueName("node"); name != "amqpga_announcement_node" {
+		t.Errorf("expected amqpga_announcement_node, got %v", name)
+	}
+}
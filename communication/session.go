@@ -0,0 +1,411 @@
+package communication
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/streadway/amqp"
+)
+
+// Default delays between two reconnection attempts, doubled after each failure.
+const (
+	DefaultMinBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// Keeps a connection and a channel to the broker alive. When the connection
+// drops, it reconnects with an exponential backoff, declares again the
+// exchanges and queues through the setup function and registers again the
+// consumers, each on an AMQP channel of its own, whose deliveries keep flowing
+// on the same Go channels.
+type Session struct {
+	Host       string
+	Setup      func(channel *amqp.Channel) error
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	mutex      sync.Mutex
+	connection *amqp.Connection
+	channel    *amqp.Channel
+	connected  chan bool
+	listeners  []chan bool
+	done       chan bool
+}
+
+// Connects to the broker and starts watching the connection. The setup
+// function is called on every new channel.
+func NewSession(host string, setup func(channel *amqp.Channel) error) (*Session, error) {
+	session := &Session{
+		Host:       host,
+		Setup:      setup,
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+		connected:  make(chan bool),
+		done:       make(chan bool),
+	}
+
+	closed, err := session.connect()
+	if err != nil {
+		return nil, err
+	}
+	go session.watch(closed)
+
+	return session, nil
+}
+
+// Dials the broker, opens a channel and sets it up, returning the channel
+// notifying the connection or channel closing.
+func (session *Session) connect() (chan *amqp.Error, error) {
+	connection, err := Connect(session.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := OpenChannel(connection)
+	if err != nil {
+		connection.Close()
+		return nil, err
+	}
+
+	if session.Setup != nil {
+		if err := session.Setup(channel); err != nil {
+			connection.Close()
+			return nil, err
+		}
+	}
+
+	closed := make(chan *amqp.Error, 2)
+	connection.NotifyClose(closed)
+	channel.NotifyClose(closed)
+
+	session.mutex.Lock()
+	session.connection = connection
+	session.channel = channel
+	close(session.connected)
+	session.mutex.Unlock()
+
+	return closed, nil
+}
+
+// Reconnects every time the connection or the channel closes.
+func (session *Session) watch(closed chan *amqp.Error) {
+	for {
+		select {
+		case reason := <-closed:
+			log.WithFields(log.Fields{
+				"reason": reason,
+			}).Warn("Connection to RabbitMQ lost")
+
+			session.mutex.Lock()
+			session.connected = make(chan bool)
+			session.connection.Close()
+			session.mutex.Unlock()
+
+			closed = session.reconnect()
+			if closed == nil {
+				return
+			}
+
+			log.Info("Reconnected to RabbitMQ")
+			session.notify()
+		case <-session.done:
+			return
+		}
+	}
+}
+
+// Tries to connect until it succeeds or the session is closed.
+func (session *Session) reconnect() chan *amqp.Error {
+	backoff := session.MinBackoff
+	for {
+		select {
+		case <-time.After(backoff):
+		case <-session.done:
+			return nil
+		}
+
+		closed, err := session.connect()
+		if err == nil {
+			return closed
+		}
+
+		log.WithFields(log.Fields{
+			"error":   err,
+			"backoff": backoff,
+		}).Warn("Failed to reconnect to RabbitMQ")
+		backoff = nextBackoff(backoff, session.MinBackoff, session.MaxBackoff)
+	}
+}
+
+// Doubles the backoff within its bounds.
+func nextBackoff(backoff, minBackoff, maxBackoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff < minBackoff {
+		backoff = minBackoff
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+func (session *Session) notify() {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	for _, listener := range session.listeners {
+		select {
+		case listener <- true:
+		default:
+			// A reconnection is already pending.
+		}
+	}
+}
+
+// Returns a channel receiving a value after each reconnection, when the
+// consumers are registered again.
+func (session *Session) Reconnections() <-chan bool {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	listener := make(chan bool, 1)
+	session.listeners = append(session.listeners, listener)
+	return listener
+}
+
+// Returns the current channel, waiting for the connection if it is down.
+func (session *Session) Channel() (*amqp.Channel, error) {
+	session.mutex.Lock()
+	connected := session.connected
+	session.mutex.Unlock()
+
+	select {
+	case <-connected:
+	case <-session.done:
+		return nil, fmt.Errorf("session closed")
+	}
+
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	return session.channel, nil
+}
+
+// Returns the current connection, waiting for it if it is down.
+func (session *Session) Connection() (*amqp.Connection, error) {
+	session.mutex.Lock()
+	connected := session.connected
+	session.mutex.Unlock()
+
+	select {
+	case <-connected:
+	case <-session.done:
+		return nil, fmt.Errorf("session closed")
+	}
+
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	return session.connection, nil
+}
+
+// Runs an operation on the current channel. If the channel is closed, the
+// operation is run again once reconnected.
+func (session *Session) Do(operation func(channel *amqp.Channel) error) error {
+	for {
+		channel, err := session.Channel()
+		if err != nil {
+			return err
+		}
+
+		err = operation(channel)
+		if err == nil || !isClosedError(err) {
+			return err
+		}
+
+		// Waits for the watcher to notice the closing.
+		select {
+		case <-time.After(session.MinBackoff):
+		case <-session.done:
+			return fmt.Errorf("session closed")
+		}
+	}
+}
+
+// Consumes a queue across the reconnections. The returned channel is closed
+// only when the session is closed or the queue is deleted.
+func (session *Session) Consume(queue *amqp.Queue) <-chan amqp.Delivery {
+	return session.ConsumeUntil(queue, nil)
+}
+
+// Consumes a queue across the reconnections until the stop channel is closed,
+// giving the messages not delivered yet back to the broker. The returned
+// channel is closed when the consumer stops, the session is closed or the
+// queue is deleted.
+func (session *Session) ConsumeUntil(queue *amqp.Queue, stop <-chan bool) <-chan amqp.Delivery {
+	return session.consume(queue, 0, stop)
+}
+
+// Consumes a queue across the reconnections with a fair dispatch, receiving up
+// to prefetch messages not acknowledged yet.
+func (session *Session) ConsumeWithPrefetch(queue *amqp.Queue, prefetch int) <-chan amqp.Delivery {
+	return session.consume(queue, prefetch, nil)
+}
+
+// Consumes a queue across the reconnections until the stop channel is closed.
+// Every consumer has a channel of its own, so that its prefetch does not apply
+// to the other consumers and a deleted queue closes only its channel. A
+// positive prefetch is set on every new channel before consuming.
+func (session *Session) consume(queue *amqp.Queue, prefetch int, stop <-chan bool) <-chan amqp.Delivery {
+	deliveries := make(chan amqp.Delivery)
+
+	go func() {
+		defer close(deliveries)
+
+		backoff := session.MinBackoff
+		for {
+			connection, err := session.Connection()
+			if err != nil {
+				return
+			}
+
+			consumer := nextConsumerTag()
+			var channelClosed chan *amqp.Error
+			var messages <-chan amqp.Delivery
+			channel, err := OpenChannel(connection)
+			if err == nil {
+				channelClosed = channel.NotifyClose(make(chan *amqp.Error, 1))
+				if prefetch > 0 {
+					err = SetFairDispatch(channel, prefetch)
+				}
+			}
+			if err == nil {
+				messages, err = ConsumeQueueAs(channel, queue, consumer)
+			}
+			if err != nil {
+				if channel != nil {
+					channel.Close()
+				}
+				if isNotFoundError(err) {
+					log.WithFields(log.Fields{
+						"queue": queue.Name,
+					}).Infof("The %v queue does not exist anymore", queue.Name)
+					return
+				}
+
+				log.WithFields(log.Fields{
+					"error": err,
+					"queue": queue.Name,
+				}).Warn("Failed to consume a queue")
+
+				select {
+				case <-time.After(backoff):
+				case <-session.done:
+					return
+				case <-stop:
+					return
+				}
+				backoff = nextBackoff(backoff, session.MinBackoff, session.MaxBackoff)
+				continue
+			}
+			backoff = session.MinBackoff
+
+			if !forward(messages, deliveries, stop, session.done) {
+				channel.Cancel(consumer, false)
+				for message := range messages {
+					message.Nack(false, true)
+				}
+				channel.Close()
+				return
+			}
+
+			select {
+			case <-channelClosed:
+				// Consumes again on a new channel, once reconnected if the
+				// connection dropped.
+			default:
+				log.WithFields(log.Fields{
+					"queue": queue.Name,
+				}).Infof("Consumer of the %v queue cancelled by the broker", queue.Name)
+				channel.Close()
+				return
+			}
+		}
+	}()
+
+	return deliveries
+}
+
+// Forwards the messages until they end, returning false if it was stopped
+// before.
+func forward(messages <-chan amqp.Delivery, deliveries chan<- amqp.Delivery, stop <-chan bool, done <-chan bool) bool {
+	for {
+		select {
+		case message, ok := <-messages:
+			if !ok {
+				return true
+			}
+			select {
+			case deliveries <- message:
+			case <-stop:
+				message.Nack(false, true)
+				return false
+			case <-done:
+				return false
+			}
+		case <-stop:
+			return false
+		case <-done:
+			return false
+		}
+	}
+}
+
+var consumerTags int64
+
+// Returns a consumer tag unique within the process.
+func nextConsumerTag() string {
+	return fmt.Sprintf("amqpga-%v", atomic.AddInt64(&consumerTags, 1))
+}
+
+// Closes the connection and stops reconnecting.
+func (session *Session) Close() error {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	select {
+	case <-session.done:
+		return nil
+	default:
+	}
+	close(session.done)
+
+	if session.connection != nil {
+		return session.connection.Close()
+	}
+	return nil
+}
+
+// Tells if an error is caused by a closed connection or channel.
+func isClosedError(err error) bool {
+	if wrapped, ok := err.(*Error); ok {
+		err = wrapped.Cause
+	}
+	if err == amqp.ErrClosed {
+		return true
+	}
+	if amqpErr, ok := err.(*amqp.Error); ok {
+		return amqpErr.Code == amqp.ChannelError || amqpErr.Code == amqp.ConnectionForced
+	}
+	return false
+}
+
+// Tells if an error is caused by a missing queue or exchange.
+func isNotFoundError(err error) bool {
+	if wrapped, ok := err.(*Error); ok {
+		err = wrapped.Cause
+	}
+	amqpErr, ok := err.(*amqp.Error)
+	return ok && amqpErr.Code == amqp.NotFound
+}
@@ -0,0 +1,45 @@
+package communication
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+func TestNextBackoff(t *testing.T) {
+	cases := []struct {
+		backoff  time.Duration
+		expected time.Duration
+	}{
+		{0, time.Second},
+		{time.Second, 2 * time.Second},
+		{4 * time.Second, 8 * time.Second},
+		{6 * time.Second, 10 * time.Second},
+	}
+	for _, c := range cases {
+		if backoff := nextBackoff(c.backoff, time.Second, 10*time.Second); backoff != c.expected {
+			t.Errorf("expected %v after %v, got %v", c.expected, c.backoff, backoff)
+		}
+	}
+}
+
+func TestIsClosedError(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected bool
+	}{
+		{amqp.ErrClosed, true},
+		{wrapError(amqp.ErrClosed, "failed to publish"), true},
+		{&amqp.Error{Code: amqp.ChannelError}, true},
+		{&amqp.Error{Code: amqp.ConnectionForced}, true},
+		{&amqp.Error{Code: amqp.NotFound}, false},
+		{errors.New("malformed message"), false},
+	}
+	for _, c := range cases {
+		if closed := isClosedError(c.err); closed != c.expected {
+			t.Errorf("expected %v for %v, got %v", c.expected, c.err, closed)
+		}
+	}
+}
@@ -3,6 +3,18 @@ package config
 const (
 	RequestQueueName               = "amqpga_request"
 	ResponseQueueName              = "amqpga_response"
+	MigrationExchangeName          = "amqpga_migration"
+	EvaluationTimeHeader           = "evaluationTime"
+	ExperimentIdHeader             = "experimentId"
+	ContextHashHeader              = "contextHash"
+	IndividualIdHeader             = "individualId"
+	GenerationHeader               = "generation"
+	ChromosomeTypeHeader           = "chromosomeType"
+	BatchSizeHeader                = "batchSize"
+	DeadLetterExchangeName         = "amqpga_dead_letter"
+	DeadLetterQueueName            = "amqpga_dead_letter"
+	AnnouncementExchangeName       = "amqpga_announcement"
+	ContextExchangeName            = "amqpga_context"
 	EtcdExperimentConfigurationKey = "/services/amqpga/experiment"
 	EtcdRabbitMQConfigurationKey   = "/services/rabbitmq"
 	EtcdMongoDBConfigurationKey    = "/services/mongodb"
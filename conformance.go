@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pasqualesalza/amqpga/protocol"
+	"github.com/pasqualesalza/amqpga/transport"
+)
+
+// How long the conformance role waits for the result of a task. A refused
+// task has no result, so every refusal takes the whole timeout.
+const conformanceTimeout = 10 * time.Second
+
+// The relative tolerance of the float fitness values computed by the slaves.
+const conformanceTolerance = 1e-9
+
+// Returns a worker sending the tasks to the slaves through the transport, so
+// that the conformance suite checks the slaves serving the experiment.
+func newTransportWorker(slaves transport.Transport, experimentId string, results <-chan *transport.Message, timeout time.Duration) protocol.Worker {
+	return func(task *transport.Message) (*transport.Message, error) {
+		if err := slaves.PublishTask(experimentId, task); err != nil {
+			return nil, err
+		}
+
+		deadline := time.After(timeout)
+		for {
+			select {
+			case message, ok := <-results:
+				if !ok {
+					return nil, fmt.Errorf("transport closed while waiting for the result")
+				}
+				message.Ack()
+				// Skips the late results of the previous tasks.
+				if message.CorrelationId == task.CorrelationId {
+					return message, nil
+				}
+			case <-deadline:
+				return nil, fmt.Errorf("no result within %v", timeout)
+			}
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package ga
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/pasqualesalza/amqpga/ga/data/sat"
+)
+
+func init() {
+	RegisterProblem("onemax", newOneMaxProblem)
+	RegisterProblem("trap", newTrapProblem)
+	RegisterProblem("mmdp", newMMDPProblem)
+	RegisterProblem("nk", newNKLandscapeProblem)
+	RegisterContextProblem("nk", newNKLandscapeProblemFromContext)
+	RegisterProblem("maxsat", newMAXSATProblem)
+	RegisterContextProblem("maxsat", newMAXSATProblemFromContext)
+	RegisterProblem("knapsack-penalty", newKnapsackProblem(false))
+	RegisterContextProblem("knapsack-penalty", newKnapsackProblemFromContext(false))
+	RegisterProblem("knapsack-repair", newKnapsackProblem(true))
+	RegisterContextProblem("knapsack-repair", newKnapsackProblemFromContext(true))
+}
+
+const (
+	DefaultTrapBlockSize = 4
+	DefaultNKEpistasis   = 4
+
+	// The random formulas have 3 literals per clause and 4.27 clauses per
+	// variable, around the satisfiability threshold of 3-SAT.
+	RandomMAXSATClauseLength  = 3
+	RandomMAXSATClausesRatio  = 4.27
+	RandomKnapsackMaxWeight   = 100
+	RandomKnapsackValueSpread = 10
+)
+
+// Maximization of a function over a binary vector.
+type BinaryProblem struct {
+	FitnessFunction func(vector ByteVectorChromosome) Float64FitnessValue
+	ChromosomeSize  int
+}
+
+func (problem *BinaryProblem) NewChromosome(random *rand.Rand) Chromosome {
+	return ByteVectorChromosomeInitialization(random, problem.ChromosomeSize, BinaryFunctionMinBound, BinaryFunctionMaxBound)
+}
+
+func (problem *BinaryProblem) Bounds() (min, max interface{}) {
+	return byte(BinaryFunctionMinBound), byte(BinaryFunctionMaxBound)
+}
+
+func (problem *BinaryProblem) Evaluate(individual *Individual) FitnessValue {
+	return problem.FitnessFunction(individual.Chromosome.(ByteVectorChromosome))
+}
+
+func (problem *BinaryProblem) Minimization() bool {
+	return false
+}
+
+func (problem *BinaryProblem) Crossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	return TwoPointsCrossover(random, parent1, parent2, crossoverRate)
+}
+
+func (problem *BinaryProblem) Mutation(random *rand.Rand, individual *Individual, mutationRate float64) {
+	ByteRandomMutation(random, individual, BinaryFunctionMinBound, BinaryFunctionMaxBound, mutationRate)
+}
+
+func newOneMaxProblem(parameters ProblemParameters) (Problem, error) {
+	return &BinaryProblem{
+		FitnessFunction: OneMaxFitnessFunction,
+		ChromosomeSize:  parameters.ChromosomeSize,
+	}, nil
+}
+
+func newTrapProblem(parameters ProblemParameters) (Problem, error) {
+	blockSize := parameters.BlockSize
+	if blockSize == 0 {
+		blockSize = DefaultTrapBlockSize
+	}
+	if blockSize < 0 || parameters.ChromosomeSize%blockSize != 0 {
+		return nil, fmt.Errorf("the chromosome size %v is not a multiple of the trap block size %v", parameters.ChromosomeSize, blockSize)
+	}
+
+	return &BinaryProblem{
+		FitnessFunction: func(vector ByteVectorChromosome) Float64FitnessValue {
+			return TrapFitnessFunction(vector, blockSize)
+		},
+		ChromosomeSize: parameters.ChromosomeSize,
+	}, nil
+}
+
+func newMMDPProblem(parameters ProblemParameters) (Problem, error) {
+	if parameters.ChromosomeSize%MMDPBlockSize != 0 {
+		return nil, fmt.Errorf("the chromosome size %v is not a multiple of the MMDP block size %v", parameters.ChromosomeSize, MMDPBlockSize)
+	}
+
+	return &BinaryProblem{
+		FitnessFunction: MMDPFitnessFunction,
+		ChromosomeSize:  parameters.ChromosomeSize,
+	}, nil
+}
+
+// The neighbours of the genes of an NK-landscape and the contributions of the
+// genes, indexed by the bits of the gene and its neighbours.
+type NKLandscape struct {
+	Neighbours    [][]int
+	Contributions [][]float64
+}
+
+// Generates an NK-landscape whose genes have random neighbours and uniform
+// contributions.
+func NewNKLandscape(random *rand.Rand, size int, epistasis int) *NKLandscape {
+	landscape := &NKLandscape{
+		Neighbours:    make([][]int, size),
+		Contributions: make([][]float64, size),
+	}
+	for i := 0; i < size; i++ {
+		// Draws among the other genes, skipping the gene itself.
+		landscape.Neighbours[i] = random.Perm(size - 1)[:epistasis]
+		for j, neighbour := range landscape.Neighbours[i] {
+			if neighbour >= i {
+				landscape.Neighbours[i][j] = neighbour + 1
+			}
+		}
+
+		landscape.Contributions[i] = make([]float64, 1<<uint(epistasis+1))
+		for j := range landscape.Contributions[i] {
+			landscape.Contributions[i][j] = random.Float64()
+		}
+	}
+	return landscape
+}
+
+// Maximization of an NK-landscape, whose ruggedness grows with the number of
+// neighbours of each gene.
+type NKLandscapeProblem struct {
+	BinaryProblem
+	Landscape *NKLandscape
+}
+
+// Generates the landscape from the random seed.
+func newNKLandscapeProblem(parameters ProblemParameters) (Problem, error) {
+	epistasis := parameters.Epistasis
+	if epistasis == 0 {
+		epistasis = DefaultNKEpistasis
+	}
+	if epistasis < 0 || epistasis >= parameters.ChromosomeSize {
+		return nil, fmt.Errorf("the NK epistasis %v has to be lower than the chromosome size %v", epistasis, parameters.ChromosomeSize)
+	}
+
+	landscape := NewNKLandscape(NewRandom(parameters.RandomSeed, ProblemStream), parameters.ChromosomeSize, epistasis)
+	return buildNKLandscapeProblem(landscape), nil
+}
+
+func newNKLandscapeProblemFromContext(parameters ProblemParameters, data interface{}) (Problem, error) {
+	landscape, ok := data.(*NKLandscape)
+	if !ok || len(landscape.Neighbours) != len(landscape.Contributions) {
+		return nil, fmt.Errorf("invalid NK-landscape context %T", data)
+	}
+	return buildNKLandscapeProblem(landscape), nil
+}
+
+func buildNKLandscapeProblem(landscape *NKLandscape) *NKLandscapeProblem {
+	problem := &NKLandscapeProblem{
+		BinaryProblem: BinaryProblem{ChromosomeSize: len(landscape.Neighbours)},
+		Landscape:     landscape,
+	}
+	problem.FitnessFunction = func(vector ByteVectorChromosome) Float64FitnessValue {
+		return NKLandscapeFitnessFunction(vector, landscape)
+	}
+	return problem
+}
+
+// Returns the landscape.
+func (problem *NKLandscapeProblem) Context() interface{} {
+	return problem.Landscape
+}
+
+// Maximization of the satisfied clauses of a formula, with a gene for each
+// variable.
+type MAXSATProblem struct {
+	BinaryProblem
+	Formula *sat.Formula
+}
+
+// Loads the formula from the instance file, or generates a random 3-SAT
+// formula from the random seed.
+func newMAXSATProblem(parameters ProblemParameters) (Problem, error) {
+	if parameters.InstanceFile != "" {
+		formula, err := sat.Load(parameters.InstanceFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the MAXSAT instance %v: %v", parameters.InstanceFile, err)
+		}
+		return buildMAXSATProblem(formula), nil
+	}
+
+	if parameters.ChromosomeSize < RandomMAXSATClauseLength {
+		return nil, fmt.Errorf("the chromosome size %v is lower than the clause length %v", parameters.ChromosomeSize, RandomMAXSATClauseLength)
+	}
+	clausesNumber := int(math.Floor(RandomMAXSATClausesRatio*float64(parameters.ChromosomeSize) + 0.5))
+	formula := sat.Random(NewRandom(parameters.RandomSeed, ProblemStream), parameters.ChromosomeSize, clausesNumber, RandomMAXSATClauseLength)
+	return buildMAXSATProblem(formula), nil
+}
+
+func newMAXSATProblemFromContext(parameters ProblemParameters, data interface{}) (Problem, error) {
+	formula, ok := data.(*sat.Formula)
+	if !ok {
+		return nil, fmt.Errorf("invalid MAXSAT context %T", data)
+	}
+	return buildMAXSATProblem(formula), nil
+}
+
+func buildMAXSATProblem(formula *sat.Formula) *MAXSATProblem {
+	problem := &MAXSATProblem{
+		BinaryProblem: BinaryProblem{ChromosomeSize: formula.Variables},
+		Formula:       formula,
+	}
+	problem.FitnessFunction = func(vector ByteVectorChromosome) Float64FitnessValue {
+		return MAXSATFitnessFunction(vector, formula)
+	}
+	return problem
+}
+
+// Returns the formula.
+func (problem *MAXSATProblem) Context() interface{} {
+	return problem.Formula
+}
+
+// The items of a 0/1 knapsack problem and the capacity of the knapsack.
+type KnapsackInstance struct {
+	Weights  []int
+	Values   []int
+	Capacity int
+}
+
+// Generates a weakly correlated instance, whose values differ from the weights
+// by at most the value spread, with half the total weight as capacity.
+func NewKnapsackInstance(random *rand.Rand, size int) *KnapsackInstance {
+	instance := &KnapsackInstance{
+		Weights: make([]int, size),
+		Values:  make([]int, size),
+	}
+	totalWeight := 0
+	for i := 0; i < size; i++ {
+		instance.Weights[i] = 1 + random.Intn(RandomKnapsackMaxWeight)
+		instance.Values[i] = instance.Weights[i] - RandomKnapsackValueSpread + random.Intn(2*RandomKnapsackValueSpread+1)
+		if instance.Values[i] < 1 {
+			instance.Values[i] = 1
+		}
+		totalWeight += instance.Weights[i]
+	}
+	instance.Capacity = totalWeight / 2
+	return instance
+}
+
+// Returns the value and the weight of the items in the knapsack.
+func (instance *KnapsackInstance) Fill(vector ByteVectorChromosome) (value int, weight int) {
+	for i, x := range vector {
+		if x == 1 {
+			value += instance.Values[i]
+			weight += instance.Weights[i]
+		}
+	}
+	return value, weight
+}
+
+// Maximization of the value of the items in a knapsack. The solutions over the
+// capacity are either penalized or repaired, removing the items of lower value
+// per weight unit first.
+type KnapsackProblem struct {
+	BinaryProblem
+	Instance *KnapsackInstance
+	Repair   bool
+}
+
+// Generates the instance from the random seed.
+func newKnapsackProblem(repair bool) ProblemFactory {
+	return func(parameters ProblemParameters) (Problem, error) {
+		instance := NewKnapsackInstance(NewRandom(parameters.RandomSeed, ProblemStream), parameters.ChromosomeSize)
+		return buildKnapsackProblem(instance, repair), nil
+	}
+}
+
+func newKnapsackProblemFromContext(repair bool) ContextProblemFactory {
+	return func(parameters ProblemParameters, data interface{}) (Problem, error) {
+		instance, ok := data.(*KnapsackInstance)
+		if !ok || len(instance.Weights) != len(instance.Values) {
+			return nil, fmt.Errorf("invalid knapsack context %T", data)
+		}
+		return buildKnapsackProblem(instance, repair), nil
+	}
+}
+
+func buildKnapsackProblem(instance *KnapsackInstance, repair bool) *KnapsackProblem {
+	problem := &KnapsackProblem{
+		BinaryProblem: BinaryProblem{ChromosomeSize: len(instance.Weights)},
+		Instance:      instance,
+		Repair:        repair,
+	}
+
+	ratio := func(item int) float64 {
+		return float64(instance.Values[item]) / float64(instance.Weights[item])
+	}
+	if repair {
+		repairOrder := make([]int, len(instance.Weights))
+		for i := range repairOrder {
+			repairOrder[i] = i
+		}
+		sort.SliceStable(repairOrder, func(i, j int) bool {
+			return ratio(repairOrder[i]) < ratio(repairOrder[j])
+		})
+		problem.FitnessFunction = func(vector ByteVectorChromosome) Float64FitnessValue {
+			return KnapsackRepairFitnessFunction(vector, instance, repairOrder)
+		}
+	} else {
+		// The highest value per weight unit, so that no excess weight pays off.
+		penaltyCoefficient := 0.0
+		for i := range instance.Weights {
+			penaltyCoefficient = math.Max(penaltyCoefficient, ratio(i))
+		}
+		problem.FitnessFunction = func(vector ByteVectorChromosome) Float64FitnessValue {
+			return KnapsackPenaltyFitnessFunction(vector, instance, penaltyCoefficient)
+		}
+	}
+	return problem
+}
+
+// Returns the instance.
+func (problem *KnapsackProblem) Context() interface{} {
+	return problem.Instance
+}
@@ -0,0 +1,229 @@
+package ga
+
+import (
+	"math"
+)
+
+// The bounds of the variables of the CEC 2013 benchmark functions.
+const (
+	CEC2013MinBound = -100.0
+	CEC2013MaxBound = 100.0
+)
+
+// A basic function of the CEC 2013 benchmark, as its reference code computes
+// it. The variables are shifted by the shift vector, scaled from the bounds of
+// the benchmark to the ones of the function and, if the rotation matrices are
+// not nil, rotated by the first one and, by some functions, by the second one
+// as well. The optimum is the shift vector, of fitness value 0.
+type CEC2013Function func(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64
+
+// The basic functions of the CEC 2013 benchmark used by its composition
+// functions, by name.
+var CEC2013Functions = map[string]CEC2013Function{
+	"sphere":               cec2013Sphere,
+	"elliptic":             cec2013Elliptic,
+	"bent-cigar":           cec2013BentCigar,
+	"discus":               cec2013Discus,
+	"different-powers":     cec2013DifferentPowers,
+	"rosenbrock":           cec2013Rosenbrock,
+	"schaffer-f7":          cec2013SchafferF7,
+	"weierstrass":          cec2013Weierstrass,
+	"griewank":             cec2013Griewank,
+	"rastrigin":            cec2013Rastrigin,
+	"schwefel":             cec2013Schwefel,
+	"griewank-rosenbrock":  cec2013GriewankRosenbrock,
+	"expanded-schaffer-f6": cec2013ExpandedSchafferF6,
+}
+
+// The fitness value of each variable of the Schwefel function at its optimum,
+// with the opposite sign.
+const cec2013SchwefelConstant = 418.9828872724338
+
+// Shifts the variables, scales them and rotates them if the rotation matrix is
+// not nil.
+func cec2013Transform(vector []float64, shift []float64, scale float64, rotation [][]float64) []float64 {
+	transformed := ShiftFloat64Vector(vector, shift)
+	for i := range transformed {
+		transformed[i] *= scale
+	}
+	return cec2013Rotate(transformed, rotation)
+}
+
+func cec2013Rotate(vector []float64, rotation [][]float64) []float64 {
+	if rotation == nil {
+		return vector
+	}
+	return RotateFloat64Vector(vector, rotation)
+}
+
+// The oscillation transformation (T_osz), applied by the reference code to the
+// first and the last variable only.
+func cec2013Oscillate(vector []float64) []float64 {
+	oscillated := append([]float64(nil), vector...)
+	for _, i := range []int{0, len(vector) - 1} {
+		x := vector[i]
+		if x == 0 {
+			continue
+		}
+
+		c1, c2, sign := 5.5, 3.1, -1.0
+		if x > 0 {
+			c1, c2, sign = 10.0, 7.9, 1.0
+		}
+		logarithm := math.Log(math.Abs(x))
+		oscillated[i] = sign * math.Exp(logarithm+0.049*(math.Sin(c1*logarithm)+math.Sin(c2*logarithm)))
+	}
+	return oscillated
+}
+
+// The asymmetric transformation (T_asy) of the positive variables.
+func cec2013Asymmetric(vector []float64, beta float64) []float64 {
+	asymmetric := make([]float64, len(vector))
+	for i, x := range vector {
+		asymmetric[i] = x
+		if x > 0 {
+			asymmetric[i] = math.Pow(x, 1.0+beta*float64(i)/float64(len(vector)-1)*math.Sqrt(x))
+		}
+	}
+	return asymmetric
+}
+
+// Multiplies the variables by the diagonal matrix of condition number alpha
+// (Lambda^alpha).
+func cec2013Condition(vector []float64, alpha float64) []float64 {
+	conditioned := make([]float64, len(vector))
+	for i, x := range vector {
+		conditioned[i] = x * math.Pow(alpha, float64(i)/float64(len(vector)-1)/2.0)
+	}
+	return conditioned
+}
+
+func cec2013Sphere(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64 {
+	z := cec2013Transform(vector, shift, 1.0, rotation1)
+
+	result := 0.0
+	for _, x := range z {
+		result += x * x
+	}
+	return result
+}
+
+func cec2013Elliptic(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64 {
+	z := cec2013Oscillate(cec2013Transform(vector, shift, 1.0, rotation1))
+
+	result := 0.0
+	for i, x := range z {
+		result += math.Pow(10.0, 6.0*float64(i)/float64(len(z)-1)) * x * x
+	}
+	return result
+}
+
+func cec2013BentCigar(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64 {
+	z := cec2013Rotate(cec2013Asymmetric(cec2013Transform(vector, shift, 1.0, rotation1), 0.5), rotation2)
+
+	result := z[0] * z[0]
+	for _, x := range z[1:] {
+		result += 1e6 * x * x
+	}
+	return result
+}
+
+func cec2013Discus(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64 {
+	z := cec2013Oscillate(cec2013Transform(vector, shift, 1.0, rotation1))
+
+	result := 1e6 * z[0] * z[0]
+	for _, x := range z[1:] {
+		result += x * x
+	}
+	return result
+}
+
+func cec2013DifferentPowers(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64 {
+	z := cec2013Transform(vector, shift, 1.0, rotation1)
+
+	result := 0.0
+	for i, x := range z {
+		// The reference code computes the exponents with an integer division.
+		result += math.Pow(math.Abs(x), float64(2+4*i/(len(z)-1)))
+	}
+	return math.Sqrt(result)
+}
+
+func cec2013Rosenbrock(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64 {
+	z := cec2013Transform(vector, shift, 2.048/100.0, rotation1)
+	for i := range z {
+		z[i]++
+	}
+	return float64(RosenbrockFunctionFitnessEvaluation(z))
+}
+
+func cec2013SchafferF7(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64 {
+	y := cec2013Rotate(cec2013Condition(cec2013Asymmetric(cec2013Transform(vector, shift, 1.0, rotation1), 0.5), 10.0), rotation2)
+
+	result := 0.0
+	for i := 0; i < len(y)-1; i++ {
+		z := math.Sqrt(y[i]*y[i] + y[i+1]*y[i+1])
+		sin := math.Sin(50.0 * math.Pow(z, 0.2))
+		result += math.Sqrt(z) + math.Sqrt(z)*sin*sin
+	}
+	return result * result / float64(len(y)-1) / float64(len(y)-1)
+}
+
+func cec2013Weierstrass(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64 {
+	y := cec2013Rotate(cec2013Condition(cec2013Asymmetric(cec2013Transform(vector, shift, 0.5/100.0, rotation1), 0.5), 10.0), rotation2)
+	return float64(WeierstrassFunctionFitnessEvaluation(y))
+}
+
+func cec2013Griewank(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64 {
+	z := cec2013Condition(cec2013Transform(vector, shift, 600.0/100.0, rotation1), 100.0)
+
+	sum := 0.0
+	product := 1.0
+	for i, x := range z {
+		sum += x * x
+		product *= math.Cos(x / math.Sqrt(float64(i+1)))
+	}
+	return 1.0 + sum/4000.0 - product
+}
+
+func cec2013Rastrigin(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64 {
+	y := cec2013Asymmetric(cec2013Oscillate(cec2013Transform(vector, shift, 5.12/100.0, rotation1)), 0.2)
+	z := cec2013Rotate(cec2013Condition(cec2013Rotate(y, rotation2), 10.0), rotation1)
+	return float64(RastriginFunctionFitnessEvaluation(z))
+}
+
+func cec2013Schwefel(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64 {
+	z := cec2013Condition(cec2013Transform(vector, shift, 1000.0/100.0, rotation1), 10.0)
+	for i := range z {
+		z[i] += SchwefelFunctionOptimum
+	}
+	return cec2013SchwefelConstant*float64(len(z)) + float64(ModifiedSchwefelFunctionFitnessEvaluation(z))
+}
+
+func cec2013GriewankRosenbrock(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64 {
+	z := cec2013Transform(vector, shift, 5.0/100.0, rotation1)
+	for i := range z {
+		z[i]++
+	}
+
+	result := 0.0
+	for i, x := range z {
+		next := z[(i+1)%len(z)]
+		rosenbrock := 100.0*math.Pow(x*x-next, 2.0) + math.Pow(x-1.0, 2.0)
+		result += rosenbrock*rosenbrock/4000.0 - math.Cos(rosenbrock) + 1.0
+	}
+	return result
+}
+
+func cec2013ExpandedSchafferF6(vector []float64, shift []float64, rotation1 [][]float64, rotation2 [][]float64) float64 {
+	z := cec2013Rotate(cec2013Asymmetric(cec2013Transform(vector, shift, 1.0, rotation1), 0.5), rotation2)
+
+	result := 0.0
+	for i, x := range z {
+		next := z[(i+1)%len(z)]
+		squares := x*x + next*next
+		sin := math.Sin(math.Sqrt(squares))
+		result += 0.5 + (sin*sin-0.5)/math.Pow(1.0+0.001*squares, 2.0)
+	}
+	return result
+}
@@ -0,0 +1,514 @@
+package ga
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/pasqualesalza/amqpga/ga/data/cec"
+)
+
+func init() {
+	for name := range BenchmarkFunctions {
+		RegisterProblem("shifted-"+name, newShiftedProblem(name, false))
+		RegisterContextProblem("shifted-"+name, newShiftedProblemFromContext(name, false))
+		RegisterProblem("shifted-rotated-"+name, newShiftedProblem(name, true))
+		RegisterContextProblem("shifted-rotated-"+name, newShiftedProblemFromContext(name, true))
+	}
+	for name := range Compositions {
+		RegisterProblem(name, newCompositionProblem(name))
+		RegisterContextProblem(name, newCompositionProblemFromContext(name))
+	}
+}
+
+// The range of the shift vectors generated from the random seed, relative to
+// the bounds, so that the optimum is never on the bounds.
+const ShiftRange = 0.8
+
+// A continuous function with its bounds and the gene value of its optimum.
+type BenchmarkFunction struct {
+	FitnessFunction func(vector Float64VectorChromosome) Float64FitnessValue
+	MinBound        float64
+	MaxBound        float64
+	Optimum         float64
+}
+
+// The functions having shifted and rotated variants, by name. The Michalewicz
+// function has none, since the genes of its optimum differ. The Schwefel
+// function is the modified one, since the variables moved out of its bounds
+// would reach better values than the optimum.
+var BenchmarkFunctions = map[string]BenchmarkFunction{
+	"sphere":          {SphereFunctionFitnessEvaluation, SphereFunctionMinBound, SphereFunctionMaxBound, SphereFunctionOptimum},
+	"rastrigin":       {RastriginFunctionFitnessEvaluation, RastriginFunctionMinBound, RastriginFunctionMaxBound, RastriginFunctionOptimum},
+	"ackley":          {AckleyFunctionFitnessEvaluation, AckleyFunctionMinBound, AckleyFunctionMaxBound, AckleyFunctionOptimum},
+	"schwefel":        {ModifiedSchwefelFunctionFitnessEvaluation, SchwefelFunctionMinBound, SchwefelFunctionMaxBound, SchwefelFunctionOptimum},
+	"rosenbrock":      {RosenbrockFunctionFitnessEvaluation, RosenbrockFunctionMinBound, RosenbrockFunctionMaxBound, RosenbrockFunctionOptimum},
+	"griewank":        {GriewankFunctionFitnessEvaluation, GriewankFunctionMinBound, GriewankFunctionMaxBound, GriewankFunctionOptimum},
+	"levy":            {LevyFunctionFitnessEvaluation, LevyFunctionMinBound, LevyFunctionMaxBound, LevyFunctionOptimum},
+	"zakharov":        {ZakharovFunctionFitnessEvaluation, ZakharovFunctionMinBound, ZakharovFunctionMaxBound, ZakharovFunctionOptimum},
+	"styblinski-tang": {StyblinskiTangFunctionFitnessEvaluation, StyblinskiTangFunctionMinBound, StyblinskiTangFunctionMaxBound, StyblinskiTangFunctionOptimum},
+	"weierstrass":     {WeierstrassFunctionFitnessEvaluation, WeierstrassFunctionMinBound, WeierstrassFunctionMaxBound, WeierstrassFunctionOptimum},
+	"schaffer-f6":     {SchafferF6FunctionFitnessEvaluation, SchafferF6FunctionMinBound, SchafferF6FunctionMaxBound, SchafferF6FunctionOptimum},
+	"schaffer-f7":     {SchafferF7FunctionFitnessEvaluation, SchafferF7FunctionMinBound, SchafferF7FunctionMaxBound, SchafferF7FunctionOptimum},
+	"bent-cigar":      {BentCigarFunctionFitnessEvaluation, BentCigarFunctionMinBound, BentCigarFunctionMaxBound, BentCigarFunctionOptimum},
+	"katsuura":        {KatsuuraFunctionFitnessEvaluation, KatsuuraFunctionMinBound, KatsuuraFunctionMaxBound, KatsuuraFunctionOptimum},
+	"happy-cat":       {HappyCatFunctionFitnessEvaluation, HappyCatFunctionMinBound, HappyCatFunctionMaxBound, HappyCatFunctionOptimum},
+}
+
+// Computes the fitness value of the optimum with the size.
+func (function BenchmarkFunction) OptimumValue(size int) float64 {
+	vector := make(Float64VectorChromosome, size)
+	for i := range vector {
+		vector[i] = function.Optimum
+	}
+	return float64(function.FitnessFunction(vector))
+}
+
+// A component of a composition function of the CEC 2013 benchmark.
+type CompositionComponent struct {
+	// The name of the basic function, in CEC2013Functions.
+	Function string
+	Rotated  bool
+	// The width of the basin of the component.
+	Sigma float64
+	// The scale of the fitness values of the component.
+	Lambda float64
+	// The fitness value of the optimum of the component, added to the optimum
+	// of the composition.
+	Bias float64
+}
+
+// A composition of shifted and optionally rotated functions, whose global
+// optimum is the shift vector of the first component.
+type Composition struct {
+	Components []CompositionComponent
+	// The fitness value of the global optimum.
+	Optimum float64
+}
+
+// The composition functions of the CEC 2013 benchmark, by name: composition1
+// to composition8 are its functions F21 to F28.
+var Compositions = map[string]Composition{
+	"composition1": {
+		Components: []CompositionComponent{
+			{Function: "rosenbrock", Rotated: true, Sigma: 10, Lambda: 1, Bias: 0},
+			{Function: "different-powers", Rotated: true, Sigma: 20, Lambda: 1e-6, Bias: 100},
+			{Function: "bent-cigar", Rotated: true, Sigma: 30, Lambda: 1e-26, Bias: 200},
+			{Function: "discus", Rotated: true, Sigma: 40, Lambda: 1e-6, Bias: 300},
+			{Function: "sphere", Sigma: 50, Lambda: 0.1, Bias: 400},
+		},
+		Optimum: 700,
+	},
+	"composition2": {
+		Components: []CompositionComponent{
+			{Function: "schwefel", Sigma: 20, Lambda: 1, Bias: 0},
+			{Function: "schwefel", Sigma: 20, Lambda: 1, Bias: 100},
+			{Function: "schwefel", Sigma: 20, Lambda: 1, Bias: 200},
+		},
+		Optimum: 800,
+	},
+	"composition3": {
+		Components: []CompositionComponent{
+			{Function: "schwefel", Rotated: true, Sigma: 20, Lambda: 1, Bias: 0},
+			{Function: "schwefel", Rotated: true, Sigma: 20, Lambda: 1, Bias: 100},
+			{Function: "schwefel", Rotated: true, Sigma: 20, Lambda: 1, Bias: 200},
+		},
+		Optimum: 900,
+	},
+	"composition4": {
+		Components: []CompositionComponent{
+			{Function: "schwefel", Rotated: true, Sigma: 20, Lambda: 0.25, Bias: 0},
+			{Function: "rastrigin", Rotated: true, Sigma: 20, Lambda: 1, Bias: 100},
+			{Function: "weierstrass", Rotated: true, Sigma: 20, Lambda: 2.5, Bias: 200},
+		},
+		Optimum: 1000,
+	},
+	"composition5": {
+		Components: []CompositionComponent{
+			{Function: "schwefel", Rotated: true, Sigma: 10, Lambda: 0.25, Bias: 0},
+			{Function: "rastrigin", Rotated: true, Sigma: 30, Lambda: 1, Bias: 100},
+			{Function: "weierstrass", Rotated: true, Sigma: 50, Lambda: 2.5, Bias: 200},
+		},
+		Optimum: 1100,
+	},
+	"composition6": {
+		Components: []CompositionComponent{
+			{Function: "schwefel", Rotated: true, Sigma: 10, Lambda: 0.25, Bias: 0},
+			{Function: "rastrigin", Rotated: true, Sigma: 10, Lambda: 1, Bias: 100},
+			{Function: "elliptic", Rotated: true, Sigma: 10, Lambda: 1e-7, Bias: 200},
+			{Function: "weierstrass", Rotated: true, Sigma: 10, Lambda: 2.5, Bias: 300},
+			{Function: "griewank", Rotated: true, Sigma: 10, Lambda: 10, Bias: 400},
+		},
+		Optimum: 1200,
+	},
+	"composition7": {
+		Components: []CompositionComponent{
+			{Function: "griewank", Rotated: true, Sigma: 10, Lambda: 100, Bias: 0},
+			{Function: "rastrigin", Rotated: true, Sigma: 10, Lambda: 10, Bias: 100},
+			{Function: "schwefel", Rotated: true, Sigma: 10, Lambda: 2.5, Bias: 200},
+			{Function: "weierstrass", Rotated: true, Sigma: 20, Lambda: 25, Bias: 300},
+			{Function: "sphere", Sigma: 20, Lambda: 0.1, Bias: 400},
+		},
+		Optimum: 1300,
+	},
+	"composition8": {
+		Components: []CompositionComponent{
+			{Function: "griewank-rosenbrock", Rotated: true, Sigma: 10, Lambda: 2.5, Bias: 0},
+			{Function: "schaffer-f7", Rotated: true, Sigma: 20, Lambda: 2.5e-3, Bias: 100},
+			{Function: "expanded-schaffer-f6", Rotated: true, Sigma: 30, Lambda: 2.5, Bias: 200},
+			{Function: "schwefel", Rotated: true, Sigma: 40, Lambda: 5e-4, Bias: 300},
+			{Function: "sphere", Sigma: 50, Lambda: 0.1, Bias: 400},
+		},
+		Optimum: 1400,
+	},
+}
+
+// The shift vectors and the rotation matrices of a problem. The rotation
+// matrices are nil if the problem is not rotated.
+type ShiftRotation struct {
+	Shifts    [][]float64
+	Rotations [][][]float64
+}
+
+// Generates the shift vectors and the rotation matrices from the random seed,
+// or loads them from the shift and rotation files. As in the CEC 2013 code, the
+// values of the files are read in order whatever their rows, the shift vectors
+// first and the rotation matrices row by row, and the extra ones are ignored.
+func newShiftRotation(parameters ProblemParameters, shifts int, rotations int, minBound float64, maxBound float64) (*ShiftRotation, error) {
+	size := parameters.ChromosomeSize
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid chromosome size %v", size)
+	}
+
+	random := NewRandom(parameters.RandomSeed, ProblemStream)
+	shiftRotation := &ShiftRotation{Shifts: make([][]float64, shifts)}
+
+	if parameters.ShiftFile != "" {
+		values, err := loadCECValues(parameters.ShiftFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the shift file %v: %v", parameters.ShiftFile, err)
+		}
+		if len(values) < shifts*size {
+			return nil, fmt.Errorf("the shift file %v has %v values, expected %v", parameters.ShiftFile, len(values), shifts*size)
+		}
+		for i := range shiftRotation.Shifts {
+			shiftRotation.Shifts[i] = values[i*size : (i+1)*size]
+		}
+	} else {
+		for i := range shiftRotation.Shifts {
+			shiftRotation.Shifts[i] = Float64VectorChromosomeInitialization(random, size, ShiftRange*minBound, ShiftRange*maxBound)
+		}
+	}
+
+	if rotations == 0 {
+		return shiftRotation, nil
+	}
+
+	shiftRotation.Rotations = make([][][]float64, rotations)
+	if parameters.RotationFile != "" {
+		values, err := loadCECValues(parameters.RotationFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the rotation file %v: %v", parameters.RotationFile, err)
+		}
+		if len(values) < rotations*size*size {
+			return nil, fmt.Errorf("the rotation file %v has %v values, expected %v", parameters.RotationFile, len(values), rotations*size*size)
+		}
+		for i := range shiftRotation.Rotations {
+			shiftRotation.Rotations[i] = make([][]float64, size)
+			for j := range shiftRotation.Rotations[i] {
+				offset := (i*size + j) * size
+				shiftRotation.Rotations[i][j] = values[offset : offset+size]
+			}
+		}
+	} else {
+		for i := range shiftRotation.Rotations {
+			shiftRotation.Rotations[i] = RandomRotationMatrix(random, size)
+		}
+	}
+
+	return shiftRotation, nil
+}
+
+// Loads the values of a CEC data file, in order.
+func loadCECValues(path string) ([]float64, error) {
+	rows, err := cec.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []float64
+	for _, row := range rows {
+		values = append(values, row...)
+	}
+	return values, nil
+}
+
+// Checks that the shift vectors and the rotation matrices received in a context
+// fit the problem.
+func (shiftRotation *ShiftRotation) validate(size int, shifts int, rotations int) error {
+	if len(shiftRotation.Shifts) != shifts {
+		return fmt.Errorf("expected %v shift vectors, got %v", shifts, len(shiftRotation.Shifts))
+	}
+	for _, shift := range shiftRotation.Shifts {
+		if len(shift) != size {
+			return fmt.Errorf("expected shift vectors of size %v, got %v", size, len(shift))
+		}
+	}
+
+	if len(shiftRotation.Rotations) != rotations {
+		return fmt.Errorf("expected %v rotation matrices, got %v", rotations, len(shiftRotation.Rotations))
+	}
+	for _, rotation := range shiftRotation.Rotations {
+		if len(rotation) != size {
+			return fmt.Errorf("expected rotation matrices of size %v, got %v", size, len(rotation))
+		}
+		for _, row := range rotation {
+			if len(row) != size {
+				return fmt.Errorf("expected rotation matrices of size %v, got a row of %v", size, len(row))
+			}
+		}
+	}
+	return nil
+}
+
+// Returns a rotation matrix, nil if the problem is not rotated.
+func (shiftRotation *ShiftRotation) rotation(i int) [][]float64 {
+	if shiftRotation.Rotations == nil {
+		return nil
+	}
+	return shiftRotation.Rotations[i]
+}
+
+// Generates a random orthogonal matrix, orthonormalizing the rows of a matrix of
+// Gaussian values with the Gram-Schmidt process.
+func RandomRotationMatrix(random *rand.Rand, size int) [][]float64 {
+	matrix := make([][]float64, size)
+	for i := range matrix {
+		for {
+			row := make([]float64, size)
+			for j := range row {
+				row[j] = random.NormFloat64()
+			}
+
+			for _, previous := range matrix[:i] {
+				product := 0.0
+				for j := range row {
+					product += row[j] * previous[j]
+				}
+				for j := range row {
+					row[j] -= product * previous[j]
+				}
+			}
+
+			norm := 0.0
+			for _, x := range row {
+				norm += x * x
+			}
+			norm = math.Sqrt(norm)
+
+			// Draws the row again if it is almost dependent on the previous ones.
+			if norm < 1e-6 {
+				continue
+			}
+			for j := range row {
+				row[j] /= norm
+			}
+			matrix[i] = row
+			break
+		}
+	}
+	return matrix
+}
+
+// Shifts, rotates and scales a vector, then moves it to the optimum of a
+// function, so that the shift vector is mapped to the optimum.
+func transformFloat64Vector(vector []float64, shift []float64, rotation [][]float64, scale float64, optimum float64) Float64VectorChromosome {
+	transformed := ShiftFloat64Vector(vector, shift)
+	if rotation != nil {
+		transformed = RotateFloat64Vector(transformed, rotation)
+	}
+	for i := range transformed {
+		transformed[i] = transformed[i]*scale + optimum
+	}
+	return transformed
+}
+
+// Minimization of a continuous function whose optimum is moved to a shift
+// vector and whose variables are optionally rotated, so that it is no longer
+// separable.
+type ShiftedProblem struct {
+	Float64Problem
+	Function      BenchmarkFunction
+	ShiftRotation *ShiftRotation
+}
+
+func newShiftedProblem(name string, rotated bool) ProblemFactory {
+	return func(parameters ProblemParameters) (Problem, error) {
+		function := BenchmarkFunctions[name]
+		shiftRotation, err := newShiftRotation(parameters, 1, shiftedRotations(rotated), function.MinBound, function.MaxBound)
+		if err != nil {
+			return nil, err
+		}
+		return buildShiftedProblem(function, parameters.ChromosomeSize, shiftRotation), nil
+	}
+}
+
+func newShiftedProblemFromContext(name string, rotated bool) ContextProblemFactory {
+	return func(parameters ProblemParameters, data interface{}) (Problem, error) {
+		shiftRotation, ok := data.(*ShiftRotation)
+		if !ok {
+			return nil, fmt.Errorf("invalid shifted problem context %T", data)
+		}
+		if err := shiftRotation.validate(parameters.ChromosomeSize, 1, shiftedRotations(rotated)); err != nil {
+			return nil, fmt.Errorf("invalid shifted problem context: %v", err)
+		}
+		return buildShiftedProblem(BenchmarkFunctions[name], parameters.ChromosomeSize, shiftRotation), nil
+	}
+}
+
+// Returns the number of rotation matrices of a shifted problem.
+func shiftedRotations(rotated bool) int {
+	if rotated {
+		return 1
+	}
+	return 0
+}
+
+func buildShiftedProblem(function BenchmarkFunction, chromosomeSize int, shiftRotation *ShiftRotation) *ShiftedProblem {
+	problem := &ShiftedProblem{
+		Float64Problem: Float64Problem{
+			ChromosomeSize: chromosomeSize,
+			MinBound:       function.MinBound,
+			MaxBound:       function.MaxBound,
+		},
+		Function:      function,
+		ShiftRotation: shiftRotation,
+	}
+	problem.FitnessFunction = problem.evaluate
+	return problem
+}
+
+// Returns the shift vector and the rotation matrix.
+func (problem *ShiftedProblem) Context() interface{} {
+	return problem.ShiftRotation
+}
+
+func (problem *ShiftedProblem) evaluate(vector Float64VectorChromosome) Float64FitnessValue {
+	return problem.Function.FitnessFunction(transformFloat64Vector(vector, problem.ShiftRotation.Shifts[0], problem.ShiftRotation.rotation(0), 1, problem.Function.Optimum))
+}
+
+// Minimization of a composition function of the CEC 2013 benchmark. The
+// fitness value is the weighted sum of the components, each weighted by the
+// distance from its shift vector. The components of a rotated composition are
+// rotated by their own rotation matrix and, by some functions, by the one of
+// the next component as well.
+type CompositionProblem struct {
+	Float64Problem
+	Composition   Composition
+	ShiftRotation *ShiftRotation
+}
+
+func newCompositionProblem(name string) ProblemFactory {
+	return func(parameters ProblemParameters) (Problem, error) {
+		composition := Compositions[name]
+		if parameters.ChromosomeSize < 2 {
+			return nil, fmt.Errorf("invalid chromosome size %v, the composition functions need at least 2 variables", parameters.ChromosomeSize)
+		}
+		shiftRotation, err := newShiftRotation(parameters, len(composition.Components), composition.rotations(), CEC2013MinBound, CEC2013MaxBound)
+		if err != nil {
+			return nil, err
+		}
+		return buildCompositionProblem(composition, parameters.ChromosomeSize, shiftRotation), nil
+	}
+}
+
+func newCompositionProblemFromContext(name string) ContextProblemFactory {
+	return func(parameters ProblemParameters, data interface{}) (Problem, error) {
+		composition := Compositions[name]
+		shiftRotation, ok := data.(*ShiftRotation)
+		if !ok {
+			return nil, fmt.Errorf("invalid composition problem context %T", data)
+		}
+		if parameters.ChromosomeSize < 2 {
+			return nil, fmt.Errorf("invalid composition problem context: chromosome size %v", parameters.ChromosomeSize)
+		}
+		if err := shiftRotation.validate(parameters.ChromosomeSize, len(composition.Components), composition.rotations()); err != nil {
+			return nil, fmt.Errorf("invalid composition problem context: %v", err)
+		}
+		return buildCompositionProblem(composition, parameters.ChromosomeSize, shiftRotation), nil
+	}
+}
+
+func buildCompositionProblem(composition Composition, chromosomeSize int, shiftRotation *ShiftRotation) *CompositionProblem {
+	problem := &CompositionProblem{
+		Float64Problem: Float64Problem{
+			ChromosomeSize: chromosomeSize,
+			MinBound:       CEC2013MinBound,
+			MaxBound:       CEC2013MaxBound,
+		},
+		Composition:   composition,
+		ShiftRotation: shiftRotation,
+	}
+	problem.FitnessFunction = problem.evaluate
+	return problem
+}
+
+// Returns the number of rotation matrices, one more than the components if any
+// of them is rotated.
+func (composition Composition) rotations() int {
+	for _, component := range composition.Components {
+		if component.Rotated {
+			return len(composition.Components) + 1
+		}
+	}
+	return 0
+}
+
+// Returns the shift vectors and the rotation matrices of the components.
+func (problem *CompositionProblem) Context() interface{} {
+	return problem.ShiftRotation
+}
+
+func (problem *CompositionProblem) evaluate(vector Float64VectorChromosome) Float64FitnessValue {
+	components := problem.Composition.Components
+	weights := make([]float64, len(components))
+	values := make([]float64, len(components))
+
+	weightsSum := 0.0
+	for i, component := range components {
+		shift := problem.ShiftRotation.Shifts[i]
+
+		var rotation1, rotation2 [][]float64
+		if component.Rotated {
+			rotation1 = problem.ShiftRotation.rotation(i)
+			rotation2 = problem.ShiftRotation.rotation(i + 1)
+		}
+		value := CEC2013Functions[component.Function](vector, shift, rotation1, rotation2)
+		values[i] = component.Lambda*value + component.Bias
+
+		distance := 0.0
+		for j, x := range vector {
+			distance += math.Pow(x-shift[j], 2.0)
+		}
+		if distance == 0 {
+			return Float64FitnessValue(problem.Composition.Optimum + values[i])
+		}
+		weights[i] = math.Exp(-distance/(2.0*float64(len(vector))*math.Pow(component.Sigma, 2.0))) / math.Sqrt(distance)
+		weightsSum += weights[i]
+	}
+
+	// Far from every optimum all the weights vanish, so they are made equal.
+	if weightsSum == 0 {
+		for i := range weights {
+			weights[i] = 1.0
+		}
+		weightsSum = float64(len(weights))
+	}
+
+	result := 0.0
+	for i := range components {
+		result += weights[i] / weightsSum * values[i]
+	}
+
+	return Float64FitnessValue(problem.Composition.Optimum + result)
+}
@@ -0,0 +1,198 @@
+package ga
+
+import (
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRandomRotationMatrix(t *testing.T) {
+	matrix := RandomRotationMatrix(NewRandom(1, ProblemStream), 10)
+
+	// The rows are orthonormal.
+	for i := range matrix {
+		for j := range matrix {
+			product := 0.0
+			for k := range matrix[i] {
+				product += matrix[i][k] * matrix[j][k]
+			}
+			expected := 0.0
+			if i == j {
+				expected = 1.0
+			}
+			if math.Abs(product-expected) > 1e-9 {
+				t.Errorf("expected %v for rows %v and %v, got %v", expected, i, j, product)
+			}
+		}
+	}
+}
+
+func TestShiftedProblemsOptimum(t *testing.T) {
+	for name, function := range BenchmarkFunctions {
+		for _, prefix := range []string{"shifted-", "shifted-rotated-"} {
+			problem, err := NewProblem(prefix+name, testProblemParameters)
+			if err != nil {
+				t.Fatalf("%v%v: %v", prefix, name, err)
+			}
+			shifted := problem.(*ShiftedProblem)
+
+			// The optimum is moved to the shift vector, within the bounds.
+			shift := shifted.ShiftRotation.Shifts[0]
+			for _, x := range shift {
+				if x < function.MinBound || x > function.MaxBound {
+					t.Errorf("%v%v: shift %v out of bounds", prefix, name, x)
+				}
+			}
+			expected := function.OptimumValue(testProblemParameters.ChromosomeSize)
+			individual := &Individual{Chromosome: Float64VectorChromosome(shift)}
+			if actual := float64(problem.Evaluate(individual).(Float64FitnessValue)); math.Abs(actual-expected) > 1e-9 {
+				t.Errorf("%v%v: expected %v at the optimum, got %v", prefix, name, expected, actual)
+			}
+		}
+	}
+}
+
+func TestCEC2013FunctionsOptimum(t *testing.T) {
+	random := NewRandom(1, ProblemStream)
+	shift := Float64VectorChromosomeInitialization(random, 10, ShiftRange*CEC2013MinBound, ShiftRange*CEC2013MaxBound)
+	rotation1, rotation2 := RandomRotationMatrix(random, 10), RandomRotationMatrix(random, 10)
+
+	for name, function := range CEC2013Functions {
+		if value := function(shift, shift, nil, nil); math.Abs(value) > 1e-9 {
+			t.Errorf("%v: expected 0 at the optimum, got %v", name, value)
+		}
+		if value := function(shift, shift, rotation1, rotation2); math.Abs(value) > 1e-9 {
+			t.Errorf("%v: expected 0 at the rotated optimum, got %v", name, value)
+		}
+		vector := Float64VectorChromosomeInitialization(random, 10, CEC2013MinBound, CEC2013MaxBound)
+		if value := function(vector, shift, rotation1, rotation2); value <= 0 {
+			t.Errorf("%v: expected a positive value out of the optimum, got %v", name, value)
+		}
+	}
+}
+
+func TestCompositionProblemsOptimum(t *testing.T) {
+	for name, composition := range Compositions {
+		problem, err := NewProblem(name, testProblemParameters)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		shifts := problem.(*CompositionProblem).ShiftRotation.Shifts
+
+		// Every component has its bias over the optimum at its own shift vector.
+		for i, component := range composition.Components {
+			expected := composition.Optimum + component.Bias
+			individual := &Individual{Chromosome: Float64VectorChromosome(shifts[i])}
+			if actual := float64(problem.Evaluate(individual).(Float64FitnessValue)); math.Abs(actual-expected) > 1e-9 {
+				t.Errorf("%v: expected %v at the optimum of component %v, got %v", name, expected, i, actual)
+			}
+		}
+
+		// The global optimum is the one of the first component.
+		random := NewRandom(1, InitializationStream)
+		for i := 0; i < 100; i++ {
+			individual := &Individual{Chromosome: problem.NewChromosome(random)}
+			if fitness := float64(problem.Evaluate(individual).(Float64FitnessValue)); fitness < composition.Optimum {
+				t.Errorf("%v: expected a fitness value over the optimum, got %v", name, fitness)
+			}
+		}
+	}
+
+	// The compositions need at least 2 variables.
+	parameters := testProblemParameters
+	parameters.ChromosomeSize = 1
+	if _, err := NewProblem("composition1", parameters); err == nil {
+		t.Error("expected an error for a single variable")
+	}
+}
+
+// Returns a point within the bounds, half of the times on a vertex of them.
+func sampleFloat64Vector(random *rand.Rand, size int, minBound float64, maxBound float64) Float64VectorChromosome {
+	if random.Intn(2) == 0 {
+		return Float64VectorChromosomeInitialization(random, size, minBound, maxBound)
+	}
+	vector := make(Float64VectorChromosome, size)
+	for i := range vector {
+		vector[i] = minBound
+		if random.Intn(2) == 0 {
+			vector[i] = maxBound
+		}
+	}
+	return vector
+}
+
+func TestCECProblemsGlobalOptimum(t *testing.T) {
+	names := make([]string, 0)
+	for name := range BenchmarkFunctions {
+		names = append(names, "shifted-"+name, "shifted-rotated-"+name)
+	}
+	for name := range Compositions {
+		names = append(names, name)
+	}
+
+	// With few variables the sampled points cover the bounds.
+	parameters := testProblemParameters
+	parameters.ChromosomeSize = 2
+
+	for _, name := range names {
+		problem, err := NewProblem(name, parameters)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		var bounds Float64Problem
+		var shiftRotation *ShiftRotation
+		switch problem := problem.(type) {
+		case *ShiftedProblem:
+			bounds, shiftRotation = problem.Float64Problem, problem.ShiftRotation
+		case *CompositionProblem:
+			bounds, shiftRotation = problem.Float64Problem, problem.ShiftRotation
+		}
+		optimum := problem.Evaluate(&Individual{Chromosome: Float64VectorChromosome(shiftRotation.Shifts[0])}).(Float64FitnessValue)
+
+		// No point within the bounds is better than the optimum.
+		random := rand.New(rand.NewSource(1))
+		for i := 0; i < 10000; i++ {
+			individual := &Individual{Chromosome: sampleFloat64Vector(random, bounds.ChromosomeSize, bounds.MinBound, bounds.MaxBound)}
+			if fitness := problem.Evaluate(individual).(Float64FitnessValue); fitness < optimum {
+				t.Errorf("%v: expected a fitness value over the optimum %v, got %v", name, optimum, fitness)
+				break
+			}
+		}
+	}
+}
+
+func TestShiftedProblemFiles(t *testing.T) {
+	directory, err := ioutil.TempDir("", "amqpga")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	shiftFile := filepath.Join(directory, "shift.txt")
+	rotationFile := filepath.Join(directory, "rotation.txt")
+	ioutil.WriteFile(shiftFile, []byte("1.0\n-2.0 0.5\n"), 0644)
+	ioutil.WriteFile(rotationFile, []byte("0 1\n-1 0\n"), 0644)
+
+	parameters := ProblemParameters{ChromosomeSize: 2, ShiftFile: shiftFile, RotationFile: rotationFile}
+	problem, err := NewProblem("shifted-rotated-sphere", parameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The values are read regardless of the rows, and the extra ones ignored.
+	shifted := problem.(*ShiftedProblem)
+	if shift := shifted.ShiftRotation.Shifts[0]; len(shift) != 2 || shift[0] != 1.0 || shift[1] != -2.0 {
+		t.Errorf("unexpected shift vector %v", shift)
+	}
+	if fitness := problem.Evaluate(&Individual{Chromosome: Float64VectorChromosome{2.0, -2.0}}); fitness != Float64FitnessValue(1.0) {
+		t.Errorf("expected 1, got %v", fitness)
+	}
+
+	// A composition needs a shift vector for each component.
+	if _, err := NewProblem("composition1", parameters); err == nil {
+		t.Error("expected an error for a missing shift vector")
+	}
+}
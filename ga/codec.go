@@ -0,0 +1,466 @@
+package ga
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ugorji/go/codec"
+)
+
+// Encodes the batches of individuals exchanged by the nodes. The content type
+// and encoding of a message select the codec decoding it, so that the slaves
+// reply with the codec of the master.
+type Codec interface {
+	// The content type of the messages.
+	ContentType() string
+
+	// The content encoding of the messages, empty if not compressed.
+	ContentEncoding() string
+
+	Encode(individuals []*Individual) ([]byte, error)
+	Decode(data []byte) ([]*Individual, error)
+}
+
+const (
+	GobContentType     = "binary/gob"
+	JSONContentType    = "application/json"
+	MsgpackContentType = "application/msgpack"
+	RawContentType     = "application/x-float64-vector"
+
+	SnappyContentEncoding = "snappy"
+
+	DefaultCodec = "gob"
+)
+
+// The codecs, by name.
+var Codecs = map[string]Codec{
+	"gob":     GobCodec{},
+	"json":    JSONCodec{},
+	"msgpack": MsgpackCodec{},
+	"raw":     RawCodec{},
+}
+
+// Returns the sorted names of the codecs.
+func CodecNames() []string {
+	names := make([]string, 0, len(Codecs))
+	for name := range Codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Returns the codec of a message from its content type and encoding. A message
+// without content type is gob.
+func CodecFor(contentType string, contentEncoding string) (Codec, error) {
+	if contentType == "" {
+		contentType = GobContentType
+		contentEncoding = SnappyContentEncoding
+	}
+	for _, codec := range Codecs {
+		if codec.ContentType() == contentType && codec.ContentEncoding() == contentEncoding {
+			return codec, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported content type %v with encoding %q", contentType, contentEncoding)
+}
+
+// gob
+
+// Go gob compressed with snappy, only for Go nodes but for any chromosome.
+type GobCodec struct{}
+
+func (GobCodec) ContentType() string {
+	return GobContentType
+}
+
+func (GobCodec) ContentEncoding() string {
+	return SnappyContentEncoding
+}
+
+func (GobCodec) Encode(individuals []*Individual) ([]byte, error) {
+	return EncodeIndividuals(individuals)
+}
+
+func (GobCodec) Decode(data []byte) ([]*Individual, error) {
+	return DecodeIndividuals(data)
+}
+
+// The types of the chromosomes and fitness values in the language-neutral
+// form of the individuals.
+const (
+	ByteType    = "byte"
+	BitType     = "bit"
+	IntType     = "int"
+	Int64Type   = "int64"
+	Float32Type = "float32"
+	Float64Type = "float64"
+)
+
+// The language-neutral form of an individual, encoded by the codecs other than
+// gob. The genes and the fitness value are numbers whatever their type, and the
+// words of a bit vector are split in their low and high 32 bits.
+type WireIndividual struct {
+	Id             int64     `json:"id" codec:"id"`
+	Generation     int64     `json:"generation" codec:"generation"`
+	ChromosomeType string    `json:"chromosomeType" codec:"chromosomeType"`
+	Genes          []float64 `json:"genes" codec:"genes"`
+	// Empty if the individual is not evaluated.
+	FitnessType  string  `json:"fitnessType,omitempty" codec:"fitnessType,omitempty"`
+	FitnessValue float64 `json:"fitnessValue,omitempty" codec:"fitnessValue,omitempty"`
+}
+
+// Returns the language-neutral type of a vector chromosome.
+func ChromosomeType(chromosome Chromosome) (string, error) {
+	switch chromosome.(type) {
+	case ByteVectorChromosome:
+		return ByteType, nil
+	case BitVectorChromosome:
+		return BitType, nil
+	case IntVectorChromosome:
+		return IntType, nil
+	case Int64VectorChromosome:
+		return Int64Type, nil
+	case Float32VectorChromosome:
+		return Float32Type, nil
+	case Float64VectorChromosome:
+		return Float64Type, nil
+	}
+	return "", fmt.Errorf("unsupported chromosome type %T", chromosome)
+}
+
+// Converts an individual to its language-neutral form.
+func ToWire(individual *Individual) (WireIndividual, error) {
+	wire := WireIndividual{
+		Id:         individual.Id,
+		Generation: individual.Generation,
+	}
+
+	switch chromosome := individual.Chromosome.(type) {
+	case ByteVectorChromosome:
+		wire.ChromosomeType = ByteType
+		wire.Genes = make([]float64, len(chromosome))
+		for i, gene := range chromosome {
+			wire.Genes[i] = float64(gene)
+		}
+	case BitVectorChromosome:
+		wire.ChromosomeType = BitType
+		wire.Genes = make([]float64, 2*len(chromosome))
+		for i, word := range chromosome {
+			wire.Genes[2*i] = float64(word & math.MaxUint32)
+			wire.Genes[2*i+1] = float64(word >> 32)
+		}
+	case IntVectorChromosome:
+		wire.ChromosomeType = IntType
+		wire.Genes = make([]float64, len(chromosome))
+		for i, gene := range chromosome {
+			wire.Genes[i] = float64(gene)
+		}
+	case Int64VectorChromosome:
+		wire.ChromosomeType = Int64Type
+		wire.Genes = make([]float64, len(chromosome))
+		for i, gene := range chromosome {
+			wire.Genes[i] = float64(gene)
+		}
+	case Float32VectorChromosome:
+		wire.ChromosomeType = Float32Type
+		wire.Genes = make([]float64, len(chromosome))
+		for i, gene := range chromosome {
+			wire.Genes[i] = float64(gene)
+		}
+	case Float64VectorChromosome:
+		wire.ChromosomeType = Float64Type
+		wire.Genes = []float64(chromosome)
+	default:
+		return wire, fmt.Errorf("unsupported chromosome type %T", individual.Chromosome)
+	}
+
+	switch fitnessValue := individual.FitnessValue.(type) {
+	case nil:
+	case ByteFitnessValue:
+		wire.FitnessType, wire.FitnessValue = ByteType, float64(fitnessValue)
+	case IntFitnessValue:
+		wire.FitnessType, wire.FitnessValue = IntType, float64(fitnessValue)
+	case Int64FitnessValue:
+		wire.FitnessType, wire.FitnessValue = Int64Type, float64(fitnessValue)
+	case Float32FitnessValue:
+		wire.FitnessType, wire.FitnessValue = Float32Type, float64(fitnessValue)
+	case Float64FitnessValue:
+		wire.FitnessType, wire.FitnessValue = Float64Type, float64(fitnessValue)
+	default:
+		return wire, fmt.Errorf("unsupported fitness value type %T", individual.FitnessValue)
+	}
+
+	return wire, nil
+}
+
+// Converts the language-neutral form of an individual back, checking that the
+// numbers fit their types.
+func FromWire(wire WireIndividual) (*Individual, error) {
+	individual := &Individual{
+		Id:         wire.Id,
+		Generation: wire.Generation,
+	}
+
+	for _, gene := range wire.Genes {
+		if err := checkNumber(gene, wire.ChromosomeType); err != nil {
+			return nil, fmt.Errorf("invalid gene of individual %v: %v", wire.Id, err)
+		}
+		if wire.ChromosomeType == ByteType && (gene < 0 || gene > math.MaxUint8) {
+			return nil, fmt.Errorf("invalid gene of individual %v: %v is not a byte", wire.Id, gene)
+		}
+		if wire.ChromosomeType == BitType && (gene < 0 || gene > math.MaxUint32) {
+			return nil, fmt.Errorf("invalid gene of individual %v: %v is not a 32 bits word", wire.Id, gene)
+		}
+	}
+	switch wire.ChromosomeType {
+	case ByteType:
+		chromosome := make(ByteVectorChromosome, len(wire.Genes))
+		for i, gene := range wire.Genes {
+			chromosome[i] = byte(gene)
+		}
+		individual.Chromosome = chromosome
+	case BitType:
+		if len(wire.Genes)%2 != 0 {
+			return nil, fmt.Errorf("invalid genes of individual %v: odd number of 32 bits words", wire.Id)
+		}
+		chromosome := make(BitVectorChromosome, len(wire.Genes)/2)
+		for i := range chromosome {
+			chromosome[i] = uint64(wire.Genes[2*i]) | uint64(wire.Genes[2*i+1])<<32
+		}
+		individual.Chromosome = chromosome
+	case IntType:
+		chromosome := make(IntVectorChromosome, len(wire.Genes))
+		for i, gene := range wire.Genes {
+			chromosome[i] = int(gene)
+		}
+		individual.Chromosome = chromosome
+	case Int64Type:
+		chromosome := make(Int64VectorChromosome, len(wire.Genes))
+		for i, gene := range wire.Genes {
+			chromosome[i] = int64(gene)
+		}
+		individual.Chromosome = chromosome
+	case Float32Type:
+		chromosome := make(Float32VectorChromosome, len(wire.Genes))
+		for i, gene := range wire.Genes {
+			chromosome[i] = float32(gene)
+		}
+		individual.Chromosome = chromosome
+	case Float64Type:
+		individual.Chromosome = Float64VectorChromosome(wire.Genes)
+	default:
+		return nil, fmt.Errorf("unsupported chromosome type %q of individual %v", wire.ChromosomeType, wire.Id)
+	}
+
+	if wire.FitnessType == "" {
+		return individual, nil
+	}
+	if err := checkNumber(wire.FitnessValue, wire.FitnessType); err != nil {
+		return nil, fmt.Errorf("invalid fitness value of individual %v: %v", wire.Id, err)
+	}
+	switch wire.FitnessType {
+	case ByteType:
+		individual.FitnessValue = ByteFitnessValue(wire.FitnessValue)
+	case IntType:
+		individual.FitnessValue = IntFitnessValue(wire.FitnessValue)
+	case Int64Type:
+		individual.FitnessValue = Int64FitnessValue(wire.FitnessValue)
+	case Float32Type:
+		individual.FitnessValue = Float32FitnessValue(wire.FitnessValue)
+	case Float64Type:
+		individual.FitnessValue = Float64FitnessValue(wire.FitnessValue)
+	default:
+		return nil, fmt.Errorf("unsupported fitness value type %q of individual %v", wire.FitnessType, wire.Id)
+	}
+
+	return individual, nil
+}
+
+// Checks that a number of an integer type has no fractional part.
+func checkNumber(number float64, numberType string) error {
+	switch numberType {
+	case ByteType, BitType, IntType, Int64Type:
+		if number != math.Trunc(number) {
+			return fmt.Errorf("%v is not an integer", number)
+		}
+	}
+	return nil
+}
+
+func toWireBatch(individuals []*Individual) ([]WireIndividual, error) {
+	batch := make([]WireIndividual, len(individuals))
+	for i, individual := range individuals {
+		wire, err := ToWire(individual)
+		if err != nil {
+			return nil, err
+		}
+		batch[i] = wire
+	}
+	return batch, nil
+}
+
+func fromWireBatch(batch []WireIndividual) ([]*Individual, error) {
+	individuals := make([]*Individual, len(batch))
+	for i, wire := range batch {
+		individual, err := FromWire(wire)
+		if err != nil {
+			return nil, err
+		}
+		individuals[i] = individual
+	}
+	return individuals, nil
+}
+
+// JSON
+
+// A JSON array of individuals in the language-neutral form.
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string {
+	return JSONContentType
+}
+
+func (JSONCodec) ContentEncoding() string {
+	return ""
+}
+
+func (JSONCodec) Encode(individuals []*Individual) ([]byte, error) {
+	batch, err := toWireBatch(individuals)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode individuals: %v", err)
+	}
+	return data, nil
+}
+
+func (JSONCodec) Decode(data []byte) ([]*Individual, error) {
+	var batch []WireIndividual
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, fmt.Errorf("failed to decode individuals: %v", err)
+	}
+	return fromWireBatch(batch)
+}
+
+// MessagePack
+
+// A MessagePack array of individuals in the language-neutral form.
+type MsgpackCodec struct{}
+
+var msgpackHandle = &codec.MsgpackHandle{}
+
+func (MsgpackCodec) ContentType() string {
+	return MsgpackContentType
+}
+
+func (MsgpackCodec) ContentEncoding() string {
+	return ""
+}
+
+func (MsgpackCodec) Encode(individuals []*Individual) ([]byte, error) {
+	batch, err := toWireBatch(individuals)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if err := codec.NewEncoderBytes(&data, msgpackHandle).Encode(batch); err != nil {
+		return nil, fmt.Errorf("failed to encode individuals: %v", err)
+	}
+	return data, nil
+}
+
+func (MsgpackCodec) Decode(data []byte) ([]*Individual, error) {
+	var batch []WireIndividual
+	if err := codec.NewDecoderBytes(data, msgpackHandle).Decode(&batch); err != nil {
+		return nil, fmt.Errorf("failed to decode individuals: %v", err)
+	}
+	return fromWireBatch(batch)
+}
+
+// raw
+
+// Little-endian records of float64 vector individuals, cheap to read for
+// any language. Each record holds the id and the generation as int64, a byte
+// telling if the individual is evaluated, the float64 fitness value, the
+// number of genes as uint32 and the float64 genes.
+type RawCodec struct{}
+
+func (RawCodec) ContentType() string {
+	return RawContentType
+}
+
+func (RawCodec) ContentEncoding() string {
+	return ""
+}
+
+type rawHeader struct {
+	Id           int64
+	Generation   int64
+	Evaluated    uint8
+	FitnessValue float64
+	GenesNumber  uint32
+}
+
+func (RawCodec) Encode(individuals []*Individual) ([]byte, error) {
+	var buffer bytes.Buffer
+	for _, individual := range individuals {
+		chromosome, ok := individual.Chromosome.(Float64VectorChromosome)
+		if !ok {
+			return nil, fmt.Errorf("the raw codec only supports float64 vectors, not %T", individual.Chromosome)
+		}
+
+		header := rawHeader{
+			Id:          individual.Id,
+			Generation:  individual.Generation,
+			GenesNumber: uint32(len(chromosome)),
+		}
+		switch fitnessValue := individual.FitnessValue.(type) {
+		case nil:
+		case Float64FitnessValue:
+			header.Evaluated = 1
+			header.FitnessValue = float64(fitnessValue)
+		default:
+			return nil, fmt.Errorf("the raw codec only supports float64 fitness values, not %T", individual.FitnessValue)
+		}
+
+		binary.Write(&buffer, binary.LittleEndian, header)
+		binary.Write(&buffer, binary.LittleEndian, []float64(chromosome))
+	}
+	return buffer.Bytes(), nil
+}
+
+func (RawCodec) Decode(data []byte) ([]*Individual, error) {
+	reader := bytes.NewReader(data)
+	individuals := make([]*Individual, 0)
+	for reader.Len() > 0 {
+		var header rawHeader
+		if err := binary.Read(reader, binary.LittleEndian, &header); err != nil {
+			return nil, fmt.Errorf("failed to decode individuals: %v", err)
+		}
+		if int64(header.GenesNumber)*8 > int64(reader.Len()) {
+			return nil, fmt.Errorf("failed to decode individuals: %v genes of individual %v exceed the message", header.GenesNumber, header.Id)
+		}
+		chromosome := make(Float64VectorChromosome, header.GenesNumber)
+		if err := binary.Read(reader, binary.LittleEndian, []float64(chromosome)); err != nil {
+			return nil, fmt.Errorf("failed to decode individuals: %v", err)
+		}
+
+		individual := &Individual{
+			Id:         header.Id,
+			Generation: header.Generation,
+			Chromosome: chromosome,
+		}
+		if header.Evaluated != 0 {
+			individual.FitnessValue = Float64FitnessValue(header.FitnessValue)
+		}
+		individuals = append(individuals, individual)
+	}
+	return individuals, nil
+}
@@ -0,0 +1,119 @@
+package ga
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodecsRoundTrip(t *testing.T) {
+	individuals := []*Individual{
+		{Id: 1, Generation: 2, Chromosome: Float64VectorChromosome{0.5, -1.25, 3}, FitnessValue: Float64FitnessValue(4.5)},
+		{Id: 2, Generation: 2, Chromosome: Float64VectorChromosome{}},
+	}
+	for _, name := range CodecNames() {
+		codec := Codecs[name]
+		data, err := codec.Encode(individuals)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		decoded, err := codec.Decode(data)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if len(decoded) != len(individuals) {
+			t.Fatalf("%v: expected %v individuals, got %v", name, len(individuals), len(decoded))
+		}
+		for i := range individuals {
+			if decoded[i].Id != individuals[i].Id || decoded[i].Generation != individuals[i].Generation || decoded[i].FitnessValue != individuals[i].FitnessValue {
+				t.Errorf("%v: expected %v, got %v", name, individuals[i], decoded[i])
+			}
+			if len(individuals[i].Chromosome.(Float64VectorChromosome)) > 0 && !reflect.DeepEqual(decoded[i].Chromosome, individuals[i].Chromosome) {
+				t.Errorf("%v: expected %v, got %v", name, individuals[i].Chromosome, decoded[i].Chromosome)
+			}
+		}
+	}
+}
+
+func TestWireIndividualRoundTrip(t *testing.T) {
+	individuals := []*Individual{
+		{Id: 1, Chromosome: ByteVectorChromosome{0, 1, 255}, FitnessValue: ByteFitnessValue(3)},
+		{Id: 6, Chromosome: BitVectorChromosome{0xffffffffffffffff, 1 << 63}, FitnessValue: Float64FitnessValue(0.5)},
+		{Id: 2, Chromosome: IntVectorChromosome{2, 0, 1}, FitnessValue: IntFitnessValue(-7)},
+		{Id: 3, Chromosome: Int64VectorChromosome{-4, 5}, FitnessValue: Int64FitnessValue(8)},
+		{Id: 4, Chromosome: Float32VectorChromosome{0.5, 1.5}, FitnessValue: Float32FitnessValue(2.5)},
+		{Id: 5, Chromosome: Float64VectorChromosome{0.1}},
+	}
+	for _, name := range []string{"json", "msgpack"} {
+		codec := Codecs[name]
+		data, err := codec.Encode(individuals)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		decoded, err := codec.Decode(data)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if !reflect.DeepEqual(decoded, individuals) {
+			t.Errorf("%v: expected %v, got %v", name, individuals, decoded)
+		}
+	}
+}
+
+func TestCodecFor(t *testing.T) {
+	for _, name := range CodecNames() {
+		codec, err := CodecFor(Codecs[name].ContentType(), Codecs[name].ContentEncoding())
+		if err != nil || codec != Codecs[name] {
+			t.Errorf("%v: unexpected codec %v, %v", name, codec, err)
+		}
+	}
+
+	// The messages without content type are gob.
+	if codec, err := CodecFor("", ""); err != nil || codec != Codecs["gob"] {
+		t.Errorf("unexpected codec %v, %v", codec, err)
+	}
+	if _, err := CodecFor("text/plain", ""); err == nil {
+		t.Error("an unknown content type has to be refused")
+	}
+	if _, err := CodecFor(JSONContentType, SnappyContentEncoding); err == nil {
+		t.Error("an unknown content encoding has to be refused")
+	}
+}
+
+func TestCodecsMalformed(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"gob":     []byte("garbage"),
+		"json":    []byte(`[{"id": 1, "chromosomeType": "byte", "genes": [256]}]`),
+		"msgpack": []byte{0xc1},
+		"raw":     []byte{1, 2, 3},
+	} {
+		if _, err := Codecs[name].Decode(data); err == nil {
+			t.Errorf("%v: malformed data has to be refused", name)
+		}
+	}
+
+	for _, data := range []string{
+		`{"id": 1}`,
+		`[{"id": 1, "chromosomeType": "complex", "genes": [1]}]`,
+		`[{"id": 1, "chromosomeType": "int", "genes": [1.5]}]`,
+		`[{"id": 1, "chromosomeType": "int", "genes": [1], "fitnessType": "int", "fitnessValue": 0.5}]`,
+		`[{"id": 1, "chromosomeType": "int", "genes": [1], "fitnessType": "complex"}]`,
+	} {
+		if _, err := Codecs["json"].Decode([]byte(data)); err == nil {
+			t.Errorf("%v has to be refused", data)
+		}
+	}
+
+	// The raw codec refuses a gene count exceeding the message.
+	individuals := []*Individual{{Id: 1, Chromosome: Float64VectorChromosome{1, 2}}}
+	data, _ := Codecs["raw"].Encode(individuals)
+	if _, err := Codecs["raw"].Decode(data[:len(data)-1]); err == nil {
+		t.Error("a truncated raw message has to be refused")
+	}
+}
+
+func TestRawCodecUnsupportedChromosome(t *testing.T) {
+	individuals := []*Individual{{Id: 1, Chromosome: ByteVectorChromosome{1}}}
+	if _, err := Codecs["raw"].Encode(individuals); err == nil {
+		t.Error("a byte vector has to be refused")
+	}
+}
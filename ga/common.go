@@ -2,6 +2,7 @@ package ga
 
 import (
 	"encoding/gob"
+	"math/rand"
 
 	"github.com/pasqualesalza/amqpga/util"
 )
@@ -16,14 +17,65 @@ func (fitnessValue ByteFitnessValue) Less(other FitnessValue) bool {
 
 type ByteVectorChromosome []byte
 
-func ByteVectorChromosomeInitialization(size int, min, max byte) ByteVectorChromosome {
+func ByteVectorChromosomeInitialization(random *rand.Rand, size int, min, max byte) ByteVectorChromosome {
 	chromosome := make(ByteVectorChromosome, size)
 	for i := 0; i < size; i++ {
-		chromosome[i] = util.RandomByteInRange(min, max)
+		chromosome[i] = util.RandomByteInRange(random, min, max)
 	}
 	return chromosome
 }
 
+// bit
+
+// A vector of bits packed in 64-bit words, with the bit i in the word i/64. The
+// bits beyond the size of the chromosome are zero.
+type BitVectorChromosome []uint64
+
+// Creates a random bit vector, drawing the same numbers as the byte version so
+// that a seed gives the same bits.
+func BitVectorChromosomeInitialization(random *rand.Rand, size int) BitVectorChromosome {
+	chromosome := make(BitVectorChromosome, bitVectorWords(size))
+	for i := 0; i < size; i++ {
+		chromosome.SetBit(i, util.RandomByteInRange(random, 0, 1))
+	}
+	return chromosome
+}
+
+// Packs a vector of zeros and ones.
+func NewBitVectorChromosome(vector ByteVectorChromosome) BitVectorChromosome {
+	chromosome := make(BitVectorChromosome, bitVectorWords(len(vector)))
+	for i, x := range vector {
+		chromosome.SetBit(i, x)
+	}
+	return chromosome
+}
+
+// Returns the number of words holding the bits.
+func bitVectorWords(size int) int {
+	return (size + 63) / 64
+}
+
+func (chromosome BitVectorChromosome) Bit(i int) byte {
+	return byte(chromosome[i/64] >> uint(i%64) & 1)
+}
+
+func (chromosome BitVectorChromosome) SetBit(i int, value byte) {
+	if value == 0 {
+		chromosome[i/64] &^= 1 << uint(i%64)
+	} else {
+		chromosome[i/64] |= 1 << uint(i%64)
+	}
+}
+
+// Unpacks the first bits to a vector of zeros and ones.
+func (chromosome BitVectorChromosome) Bytes(size int) ByteVectorChromosome {
+	vector := make(ByteVectorChromosome, size)
+	for i := range vector {
+		vector[i] = chromosome.Bit(i)
+	}
+	return vector
+}
+
 // int
 
 type IntFitnessValue int
@@ -34,10 +86,31 @@ func (fitnessValue IntFitnessValue) Less(other FitnessValue) bool {
 
 type IntVectorChromosome []int
 
-func IntVectorChromosomeInitialization(size int, min, max int) IntVectorChromosome {
+func IntVectorChromosomeInitialization(random *rand.Rand, size int, min, max int) IntVectorChromosome {
 	chromosome := make(IntVectorChromosome, size)
 	for i := 0; i < size; i++ {
-		chromosome[i] = util.RandomIntInRange(min, max)
+		chromosome[i] = util.RandomIntInRange(random, min, max)
+	}
+	return chromosome
+}
+
+// Creates a random permutation of [0, size).
+func PermutationInitialization(random *rand.Rand, size int) IntVectorChromosome {
+	return IntVectorChromosome(random.Perm(size))
+}
+
+// Creates a random permutation with repetition, where each value i of
+// [0, len(repetitions)) appears repetitions[i] times.
+func PermutationWithRepetitionInitialization(random *rand.Rand, repetitions []int) IntVectorChromosome {
+	chromosome := make(IntVectorChromosome, 0)
+	for value, repetition := range repetitions {
+		for i := 0; i < repetition; i++ {
+			chromosome = append(chromosome, value)
+		}
+	}
+	for i := len(chromosome) - 1; i > 0; i-- {
+		j := random.Intn(i + 1)
+		chromosome[i], chromosome[j] = chromosome[j], chromosome[i]
 	}
 	return chromosome
 }
@@ -52,10 +125,10 @@ func (fitnessValue Int64FitnessValue) Less(other FitnessValue) bool {
 
 type Int64VectorChromosome []int64
 
-func Int64VectorChromosomeInitialization(size int, min, max int64) Int64VectorChromosome {
+func Int64VectorChromosomeInitialization(random *rand.Rand, size int, min, max int64) Int64VectorChromosome {
 	chromosome := make(Int64VectorChromosome, size)
 	for i := 0; i < size; i++ {
-		chromosome[i] = util.RandomInt64InRange(min, max)
+		chromosome[i] = util.RandomInt64InRange(random, min, max)
 	}
 	return chromosome
 }
@@ -70,10 +143,10 @@ func (fitnessValue Float32FitnessValue) Less(other FitnessValue) bool {
 
 type Float32VectorChromosome []float32
 
-func Float32VectorChromosomeInitialization(size int, min, max float32) Float32VectorChromosome {
+func Float32VectorChromosomeInitialization(random *rand.Rand, size int, min, max float32) Float32VectorChromosome {
 	chromosome := make(Float32VectorChromosome, size)
 	for i := 0; i < size; i++ {
-		chromosome[i] = util.RandomFloat32InRange(min, max)
+		chromosome[i] = util.RandomFloat32InRange(random, min, max)
 	}
 	return chromosome
 }
@@ -88,10 +161,10 @@ func (fitnessValue Float64FitnessValue) Less(other FitnessValue) bool {
 
 type Float64VectorChromosome []float64
 
-func Float64VectorChromosomeInitialization(size int, min float64, max float64) Float64VectorChromosome {
+func Float64VectorChromosomeInitialization(random *rand.Rand, size int, min float64, max float64) Float64VectorChromosome {
 	chromosome := make(Float64VectorChromosome, size)
 	for i := 0; i < size; i++ {
-		chromosome[i] = util.RandomFloat64InRange(min, max)
+		chromosome[i] = util.RandomFloat64InRange(random, min, max)
 	}
 	return chromosome
 }
@@ -100,6 +173,8 @@ func init() {
 	gob.Register(ByteFitnessValue(0))
 	gob.Register(ByteVectorChromosome{})
 
+	gob.Register(BitVectorChromosome{})
+
 	gob.Register(IntFitnessValue(0))
 	gob.Register(IntVectorChromosome{})
 
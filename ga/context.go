@@ -0,0 +1,120 @@
+package ga
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/golang/snappy"
+
+	"github.com/pasqualesalza/amqpga/ga/data/sat"
+	"github.com/pasqualesalza/amqpga/ga/data/tsp"
+)
+
+// The version of the problem context format. A context of another version is
+// refused.
+const ProblemContextVersion = 1
+
+// A problem depending on data generated or loaded when it is built, such as
+// random peaks or an instance file, which has to be shipped to the other nodes
+// instead of being built again.
+type ContextProblem interface {
+	Problem
+
+	// Returns the data of the problem, registered with gob.
+	Context() interface{}
+}
+
+// Builds a problem from its parameters and the data of its context.
+type ContextProblemFactory func(parameters ProblemParameters, data interface{}) (Problem, error)
+
+var contextProblemFactories = make(map[string]ContextProblemFactory)
+
+// Registers the factory building a problem from its context. It panics if the
+// name is already registered.
+func RegisterContextProblem(name string, factory ContextProblemFactory) {
+	if _, ok := contextProblemFactories[name]; ok {
+		panic(fmt.Sprintf("context problem %v already registered", name))
+	}
+	contextProblemFactories[name] = factory
+}
+
+// Everything needed to evaluate the individuals of an experiment exactly as
+// its master does.
+type ProblemContext struct {
+	Version      int
+	ExperimentId string
+	Descriptor   ProblemDescriptor
+	// The data of a context problem, nil for the others.
+	Data interface{}
+}
+
+// Builds the context of the problem of an experiment.
+func NewProblemContext(experimentId string, descriptor ProblemDescriptor, problem Problem) *ProblemContext {
+	context := &ProblemContext{
+		Version:      ProblemContextVersion,
+		ExperimentId: experimentId,
+		Descriptor:   descriptor,
+	}
+	if contextProblem, ok := problem.(ContextProblem); ok {
+		context.Data = contextProblem.Context()
+	}
+	return context
+}
+
+func (context *ProblemContext) Encode() ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := gob.NewEncoder(&buffer)
+	if err := encoder.Encode(context); err != nil {
+		return nil, fmt.Errorf("failed to encode problem context: %v", err)
+	}
+	return snappy.Encode(nil, buffer.Bytes()), nil
+}
+
+func (context *ProblemContext) Decode(data []byte) error {
+	data, err := snappy.Decode(nil, data)
+	if err != nil {
+		return fmt.Errorf("failed to decompress problem context: %v", err)
+	}
+	decoder := gob.NewDecoder(bytes.NewBuffer(data))
+	if err := decoder.Decode(context); err != nil {
+		return fmt.Errorf("failed to decode problem context: %v", err)
+	}
+	if context.Version != ProblemContextVersion {
+		return fmt.Errorf("unsupported problem context version %v", context.Version)
+	}
+	return nil
+}
+
+// Returns the hash identifying an encoded context.
+func ProblemContextHash(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// Builds the problem of the context, using its data instead of generating or
+// loading it again.
+func (context *ProblemContext) NewProblem() (Problem, error) {
+	if context.Data == nil {
+		return context.Descriptor.NewProblem()
+	}
+
+	factory, ok := contextProblemFactories[context.Descriptor.Name]
+	if !ok {
+		return nil, fmt.Errorf("problem %v cannot be built from a context", context.Descriptor.Name)
+	}
+	return factory(context.Descriptor.Parameters, context.Data)
+}
+
+func init() {
+	gob.Register([]ByteVectorChromosome{})
+	gob.Register([]BitVectorChromosome{})
+	gob.Register(&tsp.Instance{})
+	gob.Register([][][2]int{})
+	gob.Register(&ShiftRotation{})
+	gob.Register(&NKLandscape{})
+	gob.Register(&sat.Formula{})
+	gob.Register(&KnapsackInstance{})
+}
@@ -0,0 +1,74 @@
+package ga
+
+import (
+	"testing"
+)
+
+func TestProblemContextRoundTrip(t *testing.T) {
+	for _, name := range []string{"ppeaks", "tsp", "jss", "sphere", "shifted-rotated-rastrigin", "composition3", "nk", "maxsat", "knapsack-repair", "ppeaks-bits"} {
+		descriptor := ProblemDescriptor{Name: name, Parameters: testProblemParameters}
+		problem, err := descriptor.NewProblem()
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		data, err := NewProblemContext("abc", descriptor, problem).Encode()
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		context := new(ProblemContext)
+		if err := context.Decode(data); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if context.ExperimentId != "abc" || context.Descriptor != descriptor {
+			t.Errorf("%v: unexpected context %v", name, context)
+		}
+
+		rebuilt, err := context.NewProblem()
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		// Both problems evaluate the same landscape.
+		for i := 0; i < 10; i++ {
+			individual := &Individual{Chromosome: problem.NewChromosome(NewRandom(0, InitializationStream, int64(i)))}
+			if expected, actual := problem.Evaluate(individual), rebuilt.Evaluate(individual); expected != actual {
+				t.Errorf("%v: expected %v, got %v", name, expected, actual)
+			}
+		}
+	}
+}
+
+func TestProblemContextHash(t *testing.T) {
+	descriptor := ProblemDescriptor{Name: "ppeaks", Parameters: testProblemParameters}
+	first, _ := descriptor.NewProblem()
+	second, _ := descriptor.NewProblem()
+	otherDescriptor := descriptor
+	otherDescriptor.Parameters.RandomSeed++
+	other, _ := otherDescriptor.NewProblem()
+
+	firstData, _ := NewProblemContext("abc", descriptor, first).Encode()
+	secondData, _ := NewProblemContext("abc", descriptor, second).Encode()
+	otherData, _ := NewProblemContext("abc", otherDescriptor, other).Encode()
+	// The peaks are drawn from the seed, so the same seed gives the same landscape.
+	if ProblemContextHash(firstData) != ProblemContextHash(secondData) {
+		t.Error("the hash of the same context has to be the same")
+	}
+	if ProblemContextHash(firstData) == ProblemContextHash(otherData) {
+		t.Error("the hashes of different contexts have to differ")
+	}
+}
+
+func TestProblemContextDecodeUnsupportedVersion(t *testing.T) {
+	context := &ProblemContext{Version: ProblemContextVersion + 1, Descriptor: ProblemDescriptor{Name: "sphere"}}
+	data, err := context.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := new(ProblemContext).Decode(data); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+	if err := new(ProblemContext).Decode([]byte("malformed")); err == nil {
+		t.Error("expected an error for a malformed context")
+	}
+}
@@ -0,0 +1,29 @@
+package cec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	rows, err := Parse(strings.NewReader(`  1.5e+001 -2.0000000000000000e+000
+ 
+3 4
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 || len(rows[0]) != 2 || len(rows[1]) != 2 {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+	if rows[0][0] != 15 || rows[0][1] != -2 || rows[1][0] != 3 || rows[1][1] != 4 {
+		t.Errorf("unexpected rows %v", rows)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse(strings.NewReader("1 2\n3 x\n")); err == nil {
+		t.Error("expected an error for an invalid number")
+	}
+}
@@ -0,0 +1,7 @@
+package jss
+
+// The compiled-in instances, by name.
+var Instances = map[string][][][2]int{
+	"abz5": ABZ5JSS,
+	"yn4":  YN4JSS,
+}
@@ -0,0 +1,189 @@
+package jss
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Loads a job-shop instance from a file in the OR-Library or in the Taillard format.
+func Load(path string) ([][][2]int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parses a job-shop instance in the OR-Library or in the Taillard format. The
+// operations are returned as (machine, processing time) pairs with machines
+// numbered from 0.
+//
+// In the OR-Library format, the first line holding exactly two integers gives
+// the number of jobs and machines and it is followed by a line per job with the
+// machine and processing time of each operation. Text lines before it, as the
+// instance names and descriptions of jobshop1.txt, are skipped, so only the
+// first instance of a file is parsed.
+//
+// In the Taillard format, a header line starting with "Nb of jobs" is followed
+// by the sizes, then a "Times" and a "Machines" section with a line per job and
+// machines numbered from 1.
+func Parse(reader io.Reader) ([][][2]int, error) {
+	var lines []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, line := range lines {
+		if strings.HasPrefix(strings.ToLower(line), "nb of jobs") {
+			return parseTaillard(lines[i+1:])
+		}
+	}
+	return parseORLibrary(lines)
+}
+
+// Checks that the sizes of an instance are positive.
+func validateSizes(jobs int, machines int) error {
+	if jobs <= 0 || machines <= 0 {
+		return fmt.Errorf("invalid %v jobs on %v machines", jobs, machines)
+	}
+	return nil
+}
+
+// Checks that every operation of an instance runs on one of its machines for a
+// non-negative time.
+func validateOperations(instance [][][2]int, machines int) error {
+	for job, operations := range instance {
+		for operation, pair := range operations {
+			if pair[0] < 0 || pair[0] >= machines {
+				return fmt.Errorf("invalid machine %v for operation %v of job %v", pair[0], operation, job)
+			}
+			if pair[1] < 0 {
+				return fmt.Errorf("negative time %v for operation %v of job %v", pair[1], operation, job)
+			}
+		}
+	}
+	return nil
+}
+
+// Parses the integers of a line, failing on other tokens.
+func parseIntegers(line string) ([]int, error) {
+	fields := strings.Fields(line)
+	integers := make([]int, len(fields))
+	for i, field := range fields {
+		integer, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		integers[i] = integer
+	}
+	return integers, nil
+}
+
+func parseORLibrary(lines []string) ([][][2]int, error) {
+	for i, line := range lines {
+		sizes, err := parseIntegers(line)
+		if err != nil || len(sizes) != 2 {
+			continue
+		}
+		jobs, machines := sizes[0], sizes[1]
+		if err := validateSizes(jobs, machines); err != nil {
+			return nil, err
+		}
+
+		if len(lines) < i+1+jobs {
+			return nil, fmt.Errorf("expected %v jobs, got %v", jobs, len(lines)-i-1)
+		}
+
+		instance := make([][][2]int, jobs)
+		for job := 0; job < jobs; job++ {
+			values, err := parseIntegers(lines[i+1+job])
+			if err != nil {
+				return nil, fmt.Errorf("invalid job %v: %v", job, err)
+			}
+			if len(values) != 2*machines {
+				return nil, fmt.Errorf("expected %v values for job %v, got %v", 2*machines, job, len(values))
+			}
+
+			instance[job] = make([][2]int, machines)
+			for operation := 0; operation < machines; operation++ {
+				instance[job][operation] = [2]int{values[2*operation], values[2*operation+1]}
+			}
+		}
+
+		if err := validateOperations(instance, machines); err != nil {
+			return nil, err
+		}
+		return instance, nil
+	}
+
+	return nil, fmt.Errorf("missing the number of jobs and machines")
+}
+
+func parseTaillard(lines []string) ([][][2]int, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("missing the number of jobs and machines")
+	}
+	sizes, err := parseIntegers(lines[0])
+	if err != nil || len(sizes) < 2 {
+		return nil, fmt.Errorf("invalid sizes line %q", lines[0])
+	}
+	jobs, machines := sizes[0], sizes[1]
+	if err := validateSizes(jobs, machines); err != nil {
+		return nil, err
+	}
+
+	// Reads a section of a line per job.
+	readSection := func(name string, start int) ([][]int, error) {
+		if len(lines) < start+1+jobs || !strings.EqualFold(lines[start], name) {
+			return nil, fmt.Errorf("missing the %v section", name)
+		}
+		section := make([][]int, jobs)
+		for job := 0; job < jobs; job++ {
+			values, err := parseIntegers(lines[start+1+job])
+			if err != nil {
+				return nil, fmt.Errorf("invalid job %v in the %v section: %v", job, name, err)
+			}
+			if len(values) != machines {
+				return nil, fmt.Errorf("expected %v values for job %v in the %v section, got %v", machines, job, name, len(values))
+			}
+			section[job] = values
+		}
+		return section, nil
+	}
+
+	times, err := readSection("Times", 1)
+	if err != nil {
+		return nil, err
+	}
+	machineIds, err := readSection("Machines", 2+jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	instance := make([][][2]int, jobs)
+	for job := 0; job < jobs; job++ {
+		instance[job] = make([][2]int, machines)
+		for operation := 0; operation < machines; operation++ {
+			instance[job][operation] = [2]int{machineIds[job][operation] - 1, times[job][operation]}
+		}
+	}
+
+	if err := validateOperations(instance, machines); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
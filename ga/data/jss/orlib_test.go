@@ -0,0 +1,74 @@
+package jss
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var expectedInstance = [][][2]int{
+	{{0, 3}, {1, 2}, {2, 2}},
+	{{0, 2}, {2, 1}, {1, 4}},
+}
+
+func TestParseORLibrary(t *testing.T) {
+	instance, err := Parse(strings.NewReader(` +++++++++++++++++++++++++++++
+ instance test
+ +++++++++++++++++++++++++++++
+ A 2x3 test instance
+ 2 3
+ 0 3 1 2 2 2
+ 0 2 2 1 1 4
+ +++++++++++++++++++++++++++++
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(instance, expectedInstance) {
+		t.Errorf("expected %v, got %v", expectedInstance, instance)
+	}
+}
+
+func TestParseTaillard(t *testing.T) {
+	instance, err := Parse(strings.NewReader(`Nb of jobs, Nb of Machines, Time seed, Machine seed, Upper bound, Lower bound
+          2           3   840612802   398197754        1231        1005
+Times
+ 3 2 2
+ 2 1 4
+Machines
+ 1 2 3
+ 1 3 2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(instance, expectedInstance) {
+		t.Errorf("expected %v, got %v", expectedInstance, instance)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	inputs := []string{
+		"no sizes here\n",
+		"2 3\n0 3 1 2 2 2\n",
+		"2 3\n0 3 1 2 2 2\n0 2 2 1\n",
+		"Nb of jobs, Nb of Machines\n2 3\nTimes\n3 2 2\n2 1 4\n",
+		// Sizes that are not positive.
+		"-2 3\n",
+		"2 0\n",
+		"Nb of jobs, Nb of Machines\n-2 3\nTimes\nMachines\n",
+		// Machines out of range and negative times.
+		"2 3\n0 3 1 2 3 2\n0 2 2 1 1 4\n",
+		"2 3\n0 3 1 2 2 2\n0 2 -1 1 1 4\n",
+		"2 3\n0 3 1 -2 2 2\n0 2 2 1 1 4\n",
+		"Nb of jobs, Nb of Machines\n2 3\nTimes\n3 2 2\n2 1 4\nMachines\n0 2 3\n1 3 2\n",
+		"Nb of jobs, Nb of Machines\n2 3\nTimes\n3 2 2\n2 1 4\nMachines\n1 2 4\n1 3 2\n",
+		"Nb of jobs, Nb of Machines\n2 3\nTimes\n3 -2 2\n2 1 4\nMachines\n1 2 3\n1 3 2\n",
+	}
+
+	for _, input := range inputs {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			t.Errorf("expected an error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package sat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// A formula in conjunctive normal form. Each clause is a list of literals, the
+// positive or negative number of a variable numbered from 1.
+type Formula struct {
+	Variables int
+	Clauses   [][]int
+}
+
+// Generates a uniform random formula with clauses of distinct variables.
+func Random(random *rand.Rand, variables int, clausesNumber int, clauseLength int) *Formula {
+	formula := &Formula{Variables: variables, Clauses: make([][]int, clausesNumber)}
+	for i := range formula.Clauses {
+		clause := make([]int, clauseLength)
+		for j, variable := range random.Perm(variables)[:clauseLength] {
+			clause[j] = variable + 1
+			if random.Intn(2) == 0 {
+				clause[j] = -clause[j]
+			}
+		}
+		formula.Clauses[i] = clause
+	}
+	return formula
+}
+
+// Loads a formula from a file in the DIMACS CNF format.
+func Load(path string) (*Formula, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parses a formula in the DIMACS CNF format. The comment lines start with "c",
+// the "p cnf" line gives the number of variables and clauses, and each clause
+// is terminated by a 0, even across lines. A line starting with "%", as at the
+// end of the SATLIB files, ends the formula.
+func Parse(reader io.Reader) (*Formula, error) {
+	formula := new(Formula)
+	clausesNumber := -1
+	var clause []int
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "c") {
+			continue
+		}
+		if strings.HasPrefix(line, "%") {
+			break
+		}
+
+		fields := strings.Fields(line)
+		if fields[0] == "p" {
+			if len(fields) != 4 || fields[1] != "cnf" {
+				return nil, fmt.Errorf("invalid problem line %q", line)
+			}
+			variables, err := strconv.Atoi(fields[2])
+			if err != nil || variables <= 0 {
+				return nil, fmt.Errorf("invalid number of variables %q", fields[2])
+			}
+			clausesNumber, err = strconv.Atoi(fields[3])
+			if err != nil || clausesNumber < 0 {
+				return nil, fmt.Errorf("invalid number of clauses %q", fields[3])
+			}
+			formula.Variables = variables
+			continue
+		}
+		if clausesNumber < 0 {
+			return nil, fmt.Errorf("clause before the problem line")
+		}
+
+		for _, field := range fields {
+			literal, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid literal %q", field)
+			}
+			if literal == 0 {
+				formula.Clauses = append(formula.Clauses, clause)
+				clause = nil
+				continue
+			}
+			if literal > formula.Variables || -literal > formula.Variables {
+				return nil, fmt.Errorf("literal %v out of the %v variables", literal, formula.Variables)
+			}
+			clause = append(clause, literal)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if clausesNumber < 0 {
+		return nil, fmt.Errorf("missing problem line")
+	}
+	if len(clause) > 0 {
+		formula.Clauses = append(formula.Clauses, clause)
+	}
+	if len(formula.Clauses) != clausesNumber {
+		return nil, fmt.Errorf("expected %v clauses, got %v", clausesNumber, len(formula.Clauses))
+	}
+
+	return formula, nil
+}
@@ -0,0 +1,61 @@
+package sat
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	formula, err := Parse(strings.NewReader(`c A small formula
+p cnf 3 3
+1 -2 0
+2 3
+-1 0
+-3 0
+%
+0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if formula.Variables != 3 || len(formula.Clauses) != 3 {
+		t.Fatalf("unexpected formula %+v", formula)
+	}
+	if clause := formula.Clauses[1]; len(clause) != 3 || clause[0] != 2 || clause[1] != 3 || clause[2] != -1 {
+		t.Errorf("unexpected clause %v", clause)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, data := range []string{
+		"1 2 0\n",
+		"p cnf 2 1\n1 3 0\n",
+		"p cnf 2 2\n1 2 0\n",
+		"p cnf 2 1\n1 x 0\n",
+	} {
+		if _, err := Parse(strings.NewReader(data)); err == nil {
+			t.Errorf("expected an error for %q", data)
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	formula := Random(rand.New(rand.NewSource(1)), 5, 20, 3)
+	if formula.Variables != 5 || len(formula.Clauses) != 20 {
+		t.Fatalf("unexpected formula %+v", formula)
+	}
+	for _, clause := range formula.Clauses {
+		variables := make(map[int]bool)
+		for _, literal := range clause {
+			if literal < 0 {
+				literal = -literal
+			}
+			if literal < 1 || literal > 5 || variables[literal] {
+				t.Errorf("invalid clause %v", clause)
+			}
+			variables[literal] = true
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package tsp
+
+// The compiled-in instances, by name.
+var Instances = map[string][][2]int{
+	"a280":   A280TSP,
+	"d15112": D15112TSP,
+}
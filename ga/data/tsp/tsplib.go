@@ -0,0 +1,271 @@
+package tsp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Edge weight types of the TSPLIB format.
+const (
+	EUC2DEdgeWeightType    = "EUC_2D"
+	CEIL2DEdgeWeightType   = "CEIL_2D"
+	GEOEdgeWeightType      = "GEO"
+	ATTEdgeWeightType      = "ATT"
+	ExplicitEdgeWeightType = "EXPLICIT"
+)
+
+// A symmetric travelling salesman problem instance.
+type Instance struct {
+	Name           string
+	Dimension      int
+	EdgeWeightType string
+
+	// The node coordinates, for the geometric edge weight types.
+	Coordinates [][2]float64
+
+	// The full distance matrix, for the explicit edge weight type.
+	Weights [][]int
+}
+
+// Builds an EUC_2D instance from integer coordinates.
+func NewEuclideanInstance(name string, nodes [][2]int) *Instance {
+	coordinates := make([][2]float64, len(nodes))
+	for i, node := range nodes {
+		coordinates[i] = [2]float64{float64(node[0]), float64(node[1])}
+	}
+
+	return &Instance{
+		Name:           name,
+		Dimension:      len(nodes),
+		EdgeWeightType: EUC2DEdgeWeightType,
+		Coordinates:    coordinates,
+	}
+}
+
+// Computes the distance between two nodes as defined by TSPLIB.
+func (instance *Instance) Distance(i, j int) int {
+	switch instance.EdgeWeightType {
+	case ExplicitEdgeWeightType:
+		return instance.Weights[i][j]
+	case EUC2DEdgeWeightType:
+		xd := instance.Coordinates[i][0] - instance.Coordinates[j][0]
+		yd := instance.Coordinates[i][1] - instance.Coordinates[j][1]
+		return nint(math.Sqrt(xd*xd + yd*yd))
+	case CEIL2DEdgeWeightType:
+		xd := instance.Coordinates[i][0] - instance.Coordinates[j][0]
+		yd := instance.Coordinates[i][1] - instance.Coordinates[j][1]
+		return int(math.Ceil(math.Sqrt(xd*xd + yd*yd)))
+	case ATTEdgeWeightType:
+		xd := instance.Coordinates[i][0] - instance.Coordinates[j][0]
+		yd := instance.Coordinates[i][1] - instance.Coordinates[j][1]
+		r := math.Sqrt((xd*xd + yd*yd) / 10.0)
+		t := nint(r)
+		if float64(t) < r {
+			return t + 1
+		}
+		return t
+	case GEOEdgeWeightType:
+		latitude1, longitude1 := geographicCoordinates(instance.Coordinates[i])
+		latitude2, longitude2 := geographicCoordinates(instance.Coordinates[j])
+		q1 := math.Cos(longitude1 - longitude2)
+		q2 := math.Cos(latitude1 - latitude2)
+		q3 := math.Cos(latitude1 + latitude2)
+		return int(6378.388*math.Acos(0.5*((1.0+q1)*q2-(1.0-q1)*q3)) + 1.0)
+	}
+
+	panic(fmt.Sprintf("unsupported edge weight type %v", instance.EdgeWeightType))
+}
+
+func nint(x float64) int {
+	return int(math.Floor(x + 0.5))
+}
+
+// Converts the DDD.MM coordinates to radians.
+func geographicCoordinates(coordinates [2]float64) (float64, float64) {
+	toRadians := func(x float64) float64 {
+		degrees := math.Trunc(x)
+		minutes := x - degrees
+		return 3.141592 * (degrees + 5.0*minutes/3.0) / 180.0
+	}
+	return toRadians(coordinates[0]), toRadians(coordinates[1])
+}
+
+// Loads a TSPLIB instance from a file.
+func Load(path string) (*Instance, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parses a symmetric TSPLIB instance.
+func Parse(reader io.Reader) (*Instance, error) {
+	instance := new(Instance)
+	edgeWeightFormat := ""
+	var weights []int
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	section := ""
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if line == "EOF" {
+			break
+		}
+
+		// Data sections.
+		if strings.HasSuffix(line, "_SECTION") {
+			section = line
+			switch section {
+			case "NODE_COORD_SECTION":
+				instance.Coordinates = make([][2]float64, instance.Dimension)
+			case "EDGE_WEIGHT_SECTION", "DISPLAY_DATA_SECTION":
+			default:
+				return nil, fmt.Errorf("unsupported section %v", section)
+			}
+			continue
+		}
+
+		// Specification lines.
+		if section == "" {
+			index := strings.Index(line, ":")
+			if index < 0 {
+				return nil, fmt.Errorf("invalid specification line %q", line)
+			}
+
+			key := strings.TrimSpace(line[:index])
+			value := strings.TrimSpace(line[index+1:])
+			switch key {
+			case "NAME":
+				instance.Name = value
+			case "TYPE":
+				if value != "TSP" {
+					return nil, fmt.Errorf("unsupported problem type %v", value)
+				}
+			case "DIMENSION":
+				dimension, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid dimension %v: %v", value, err)
+				}
+				instance.Dimension = dimension
+			case "EDGE_WEIGHT_TYPE":
+				instance.EdgeWeightType = value
+			case "EDGE_WEIGHT_FORMAT":
+				edgeWeightFormat = value
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch section {
+		case "NODE_COORD_SECTION":
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("invalid node line %q", line)
+			}
+			node, err := strconv.Atoi(fields[0])
+			if err != nil || node < 1 || node > instance.Dimension {
+				return nil, fmt.Errorf("invalid node line %q", line)
+			}
+			for k := 0; k < 2; k++ {
+				coordinate, err := strconv.ParseFloat(fields[k+1], 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid node line %q: %v", line, err)
+				}
+				instance.Coordinates[node-1][k] = coordinate
+			}
+		case "EDGE_WEIGHT_SECTION":
+			for _, field := range fields {
+				weight, err := strconv.Atoi(field)
+				if err != nil {
+					return nil, fmt.Errorf("invalid edge weight %v: %v", field, err)
+				}
+				weights = append(weights, weight)
+			}
+		case "DISPLAY_DATA_SECTION":
+		default:
+			return nil, fmt.Errorf("unexpected line %q", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if instance.Dimension <= 0 {
+		return nil, fmt.Errorf("missing dimension")
+	}
+
+	switch instance.EdgeWeightType {
+	case EUC2DEdgeWeightType, CEIL2DEdgeWeightType, GEOEdgeWeightType, ATTEdgeWeightType:
+		if instance.Coordinates == nil {
+			return nil, fmt.Errorf("missing node coordinates")
+		}
+	case ExplicitEdgeWeightType:
+		matrix, err := explicitWeights(weights, edgeWeightFormat, instance.Dimension)
+		if err != nil {
+			return nil, err
+		}
+		instance.Weights = matrix
+	default:
+		return nil, fmt.Errorf("unsupported edge weight type %v", instance.EdgeWeightType)
+	}
+
+	return instance, nil
+}
+
+// Builds the full distance matrix from the weights listed in a format.
+func explicitWeights(weights []int, format string, dimension int) ([][]int, error) {
+	matrix := make([][]int, dimension)
+	for i := range matrix {
+		matrix[i] = make([]int, dimension)
+	}
+
+	// Lists the (i, j) cells in the order of the format.
+	var cells [][2]int
+	for i := 0; i < dimension; i++ {
+		switch format {
+		case "FULL_MATRIX":
+			for j := 0; j < dimension; j++ {
+				cells = append(cells, [2]int{i, j})
+			}
+		case "UPPER_ROW", "LOWER_COL":
+			for j := i + 1; j < dimension; j++ {
+				cells = append(cells, [2]int{i, j})
+			}
+		case "LOWER_ROW", "UPPER_COL":
+			for j := 0; j < i; j++ {
+				cells = append(cells, [2]int{i, j})
+			}
+		case "UPPER_DIAG_ROW", "LOWER_DIAG_COL":
+			for j := i; j < dimension; j++ {
+				cells = append(cells, [2]int{i, j})
+			}
+		case "LOWER_DIAG_ROW", "UPPER_DIAG_COL":
+			for j := 0; j <= i; j++ {
+				cells = append(cells, [2]int{i, j})
+			}
+		default:
+			return nil, fmt.Errorf("unsupported edge weight format %v", format)
+		}
+	}
+
+	if len(weights) != len(cells) {
+		return nil, fmt.Errorf("expected %v edge weights, got %v", len(cells), len(weights))
+	}
+	for k, cell := range cells {
+		matrix[cell[0]][cell[1]] = weights[k]
+		matrix[cell[1]][cell[0]] = weights[k]
+	}
+
+	return matrix, nil
+}
@@ -0,0 +1,105 @@
+package tsp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEUC2D(t *testing.T) {
+	instance, err := Parse(strings.NewReader(`NAME : square
+COMMENT : A 3-4-5 rectangle
+TYPE : TSP
+DIMENSION : 4
+EDGE_WEIGHT_TYPE : EUC_2D
+NODE_COORD_SECTION
+1 0 0
+2 0 3
+3 4 3
+4 4 0
+EOF
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if instance.Name != "square" || instance.Dimension != 4 {
+		t.Errorf("unexpected instance %+v", instance)
+	}
+	if distance := instance.Distance(0, 2); distance != 5 {
+		t.Errorf("expected 5, got %v", distance)
+	}
+	if distance := instance.Distance(1, 2); distance != 4 {
+		t.Errorf("expected 4, got %v", distance)
+	}
+}
+
+func TestParseATT(t *testing.T) {
+	instance, err := Parse(strings.NewReader(`NAME: att
+TYPE: TSP
+DIMENSION: 2
+EDGE_WEIGHT_TYPE: ATT
+NODE_COORD_SECTION
+1 0 0
+2 30 40
+EOF
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// sqrt(2500 / 10) = 15.81, rounded up.
+	if distance := instance.Distance(0, 1); distance != 16 {
+		t.Errorf("expected 16, got %v", distance)
+	}
+}
+
+func TestParseExplicit(t *testing.T) {
+	formats := map[string]string{
+		"FULL_MATRIX":    "0 1 2\n1 0 3\n2 3 0",
+		"UPPER_ROW":      "1 2\n3",
+		"LOWER_ROW":      "1\n2 3",
+		"UPPER_DIAG_ROW": "0 1 2\n0 3\n0",
+		"LOWER_DIAG_ROW": "0\n1 0\n2 3 0",
+	}
+
+	for format, weights := range formats {
+		instance, err := Parse(strings.NewReader("NAME: explicit\nTYPE: TSP\nDIMENSION: 3\nEDGE_WEIGHT_TYPE: EXPLICIT\nEDGE_WEIGHT_FORMAT: " + format + "\nEDGE_WEIGHT_SECTION\n" + weights + "\nEOF\n"))
+		if err != nil {
+			t.Fatalf("%v: %v", format, err)
+		}
+
+		expected := [][]int{{0, 1, 2}, {1, 0, 3}, {2, 3, 0}}
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				if distance := instance.Distance(i, j); distance != expected[i][j] {
+					t.Errorf("%v: expected %v between %v and %v, got %v", format, expected[i][j], i, j, distance)
+				}
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	inputs := []string{
+		"NAME: missing\nTYPE: TSP\nEDGE_WEIGHT_TYPE: EUC_2D\nEOF\n",
+		"NAME: atsp\nTYPE: ATSP\nDIMENSION: 2\nEOF\n",
+		"NAME: unsupported\nTYPE: TSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: MAN_3D\nNODE_COORD_SECTION\n1 0 0 0\n2 1 1 1\nEOF\n",
+		"NAME: short\nTYPE: TSP\nDIMENSION: 3\nEDGE_WEIGHT_TYPE: EXPLICIT\nEDGE_WEIGHT_FORMAT: UPPER_ROW\nEDGE_WEIGHT_SECTION\n1 2\nEOF\n",
+	}
+
+	for _, input := range inputs {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			t.Errorf("expected an error for %q", input)
+		}
+	}
+}
+
+func TestNewEuclideanInstance(t *testing.T) {
+	instance := NewEuclideanInstance("a280", A280TSP)
+	if instance.Dimension != len(A280TSP) {
+		t.Errorf("expected %v nodes, got %v", len(A280TSP), instance.Dimension)
+	}
+	if distance := instance.Distance(0, 1); distance != 20 {
+		t.Errorf("expected 20, got %v", distance)
+	}
+}
@@ -1,6 +1,7 @@
 package ga
 
 import (
+	"math/rand"
 	"sort"
 	"testing"
 
@@ -9,10 +10,11 @@ import (
 
 // Utility function.
 func benchmarkDataAdjustment(chromosomeSize int, individualsNumber int, b *testing.B) {
+	random := rand.New(rand.NewSource(1))
 	individuals := make([]*Individual, individualsNumber)
 	for i := 0; i < individualsNumber; i++ {
 		individuals[i] = new(Individual)
-		individuals[i].Chromosome = ByteVectorChromosomeInitialization(chromosomeSize, 0, 1)
+		individuals[i].Chromosome = ByteVectorChromosomeInitialization(random, chromosomeSize, 0, 1)
 	}
 
 	b.ResetTimer()
@@ -20,7 +22,7 @@ func benchmarkDataAdjustment(chromosomeSize int, individualsNumber int, b *testi
 		b.StopTimer()
 
 		for i := 0; i < individualsNumber; i++ {
-			individuals[i].Id = util.RandomInt64InRange(0, 1)
+			individuals[i].Id = util.RandomInt64InRange(random, 0, 1)
 		}
 
 		b.StartTimer()
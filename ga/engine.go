@@ -0,0 +1,377 @@
+package ga
+
+import (
+	"math/rand"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Phases of the genetic algorithm, named as they are reported.
+const (
+	InitializationPhase            = "initialization"
+	FitnessEvaluationPhase         = "fitnessEvaluation"
+	SelectionPhase                 = "selection"
+	CrossoverPhase                 = "crossover"
+	MutationPhase                  = "mutation"
+	GenerationPhase                = "generation"
+	SolutionFitnessEvaluationPhase = "solutionFitnessEvaluation"
+)
+
+var phaseDescriptions = map[string]string{
+	InitializationPhase:            "Initialization",
+	FitnessEvaluationPhase:         "Fitness evaluation",
+	SelectionPhase:                 "Selection",
+	CrossoverPhase:                 "Crossover",
+	MutationPhase:                  "Mutation",
+	SolutionFitnessEvaluationPhase: "Solution fitness evaluation",
+}
+
+// Evaluates the fitness values of a population, returning the evaluated
+// individuals sorted by id.
+type Evaluator interface {
+	Evaluate(population []*Individual) ([]*Individual, error)
+}
+
+// Evaluates the population one individual after the other in the current process.
+type SequentialEvaluator struct {
+	FitnessFunction func(individual *Individual) FitnessValue
+}
+
+func (evaluator *SequentialEvaluator) Evaluate(population []*Individual) ([]*Individual, error) {
+	for _, individual := range population {
+		individual.FitnessValue = evaluator.FitnessFunction(individual)
+	}
+	return population, nil
+}
+
+// Evaluates the population with a pool of goroutines in the current process,
+// so the fitness function has to be safe for concurrent use.
+type ParallelEvaluator struct {
+	FitnessFunction func(individual *Individual) FitnessValue
+	WorkersNumber   int
+}
+
+func (evaluator *ParallelEvaluator) Evaluate(population []*Individual) ([]*Individual, error) {
+	workersNumber := evaluator.WorkersNumber
+	if workersNumber < 1 {
+		workersNumber = 1
+	}
+
+	individuals := make(chan *Individual)
+	var workers sync.WaitGroup
+	for i := 0; i < workersNumber; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for individual := range individuals {
+				individual.FitnessValue = evaluator.FitnessFunction(individual)
+			}
+		}()
+	}
+
+	for _, individual := range population {
+		individuals <- individual
+	}
+	close(individuals)
+	workers.Wait()
+
+	return population, nil
+}
+
+// The genetic operators used by the engine. Every operator draws its random
+// numbers from the generator it is given.
+type Operators struct {
+	Initialization func(random *rand.Rand) Chromosome
+	Selection      func(random *rand.Rand, individuals []*Individual) *Individual
+	Crossover      func(random *rand.Rand, parent1, parent2 *Individual) (Individual, Individual)
+	Mutation       func(random *rand.Rand, individual *Individual)
+}
+
+// Callbacks invoked by the engine while running. Every hook is optional.
+type Hooks struct {
+	// Called at the end of each phase with its elapsed time.
+	PhaseFinished func(phase string, generation int64, elapsed time.Duration)
+
+	// Called after each evaluation of the population. The solution flag
+	// is set for the evaluation following the last generation.
+	PopulationEvaluated func(generation int64, population []*Individual, solution bool)
+}
+
+// Runs a generational genetic algorithm.
+type Engine struct {
+	PopulationSize    int
+	GenerationsNumber int64
+	ElitesNumber      int
+	Minimization      bool
+	Operators         Operators
+	Evaluator         Evaluator
+	Hooks             Hooks
+
+	// Derives the random generators of the individuals, so that the same seed
+	// gives the same individuals.
+	Seed int64
+
+	// Exchanges individuals with other populations before breeding, if set.
+	Migrator Migrator
+}
+
+// Starts a phase and returns the function that finishes it.
+func (engine *Engine) startPhase(phase string, generation int64) func() {
+	log.Infof("%v started", phaseDescriptions[phase])
+	startTime := time.Now()
+
+	return func() {
+		log.Infof("%v finished", phaseDescriptions[phase])
+		engine.phaseFinished(phase, generation, time.Since(startTime))
+	}
+}
+
+func (engine *Engine) phaseFinished(phase string, generation int64, elapsed time.Duration) {
+	if engine.Hooks.PhaseFinished != nil {
+		engine.Hooks.PhaseFinished(phase, generation, elapsed)
+	}
+}
+
+// Creates the initial population.
+func (engine *Engine) Initialize() []*Individual {
+	finish := engine.startPhase(InitializationPhase, 0)
+	defer finish()
+
+	population := make([]*Individual, engine.PopulationSize)
+	for j := int64(0); j < int64(engine.PopulationSize); j++ {
+		population[j] = new(Individual)
+		population[j].Id = j
+		population[j].Chromosome = engine.Operators.Initialization(NewRandom(engine.Seed, InitializationStream, j))
+	}
+
+	return population
+}
+
+// Evaluates the population of a generation.
+func (engine *Engine) Evaluate(population []*Individual, generation int64, solution bool) ([]*Individual, error) {
+	phase := FitnessEvaluationPhase
+	if solution {
+		phase = SolutionFitnessEvaluationPhase
+	}
+	finish := engine.startPhase(phase, generation)
+
+	// Sets the generation number.
+	for _, individual := range population {
+		individual.Generation = generation
+	}
+
+	// Evaluates only the individuals without a fitness value, so that the
+	// elites are not evaluated again.
+	evaluated := make([]*Individual, 0, len(population))
+	unevaluated := make([]*Individual, 0, len(population))
+	for _, individual := range population {
+		if individual.FitnessValue == nil {
+			unevaluated = append(unevaluated, individual)
+		} else {
+			evaluated = append(evaluated, individual)
+		}
+	}
+
+	if len(unevaluated) > 0 {
+		var err error
+		unevaluated, err = engine.Evaluator.Evaluate(unevaluated)
+		if err != nil {
+			finish()
+			return nil, err
+		}
+	}
+
+	// Sorts the individuals by id.
+	sortedPopulation := make(SortByIdIndividuals, 0, len(population))
+	sortedPopulation = append(sortedPopulation, evaluated...)
+	sortedPopulation = append(sortedPopulation, unevaluated...)
+	sort.Sort(sortedPopulation)
+	population = sortedPopulation
+
+	finish()
+
+	if engine.Hooks.PopulationEvaluated != nil {
+		engine.Hooks.PopulationEvaluated(generation, population, solution)
+	}
+
+	return population, nil
+}
+
+// Selects copies of the best individuals of an evaluated population.
+func (engine *Engine) Elites(population []*Individual) []*Individual {
+	elitesNumber := engine.ElitesNumber
+	if elitesNumber > len(population) {
+		elitesNumber = len(population)
+	}
+	if elitesNumber <= 0 {
+		return nil
+	}
+
+	sortedPopulation := make(SortByMinFitnessValueIndividuals, len(population))
+	copy(sortedPopulation, population)
+	sort.Stable(sortedPopulation)
+
+	elites := make([]*Individual, elitesNumber)
+	for j := 0; j < elitesNumber; j++ {
+		individual := sortedPopulation[j]
+		if !engine.Minimization {
+			individual = sortedPopulation[len(sortedPopulation)-1-j]
+		}
+		elite := *individual
+		elites[j] = &elite
+	}
+
+	return elites
+}
+
+// Breeds the next population from an evaluated one. The elites are carried
+// with their fitness values, while the offspring have to be evaluated.
+func (engine *Engine) Breed(population []*Individual, generation int64) []*Individual {
+	populationSize := len(population)
+	elites := engine.Elites(population)
+	offspringSize := populationSize - len(elites)
+
+	// Each pair of children is bred with the generator of the id of the first one.
+	randoms := make([]*rand.Rand, (offspringSize+1)/2)
+	for j := range randoms {
+		id := (generation+1)*int64(populationSize) + int64(len(elites)+2*j)
+		randoms[j] = NewRandom(engine.Seed, BreedingStream, generation, id)
+	}
+
+	// >> Selection.
+	finish := engine.startPhase(SelectionPhase, generation)
+
+	parents := make([]*Individual, offspringSize)
+	for j := 0; j < offspringSize; j++ {
+		parents[j] = engine.Operators.Selection(randoms[j/2], population)
+	}
+
+	finish()
+
+	// >> Crossover.
+	finish = engine.startPhase(CrossoverPhase, generation)
+
+	offspring := make([]*Individual, offspringSize)
+	for j := 0; j+1 < offspringSize; j += 2 {
+		child1, child2 := engine.Operators.Crossover(randoms[j/2], parents[j], parents[j+1])
+		offspring[j] = &child1
+		offspring[j+1] = &child2
+	}
+	if offspringSize%2 != 0 {
+		child := *parents[offspringSize-1]
+		offspring[offspringSize-1] = &child
+	}
+
+	// The children of the parents not crossed over share their chromosomes,
+	// which must not be changed by the mutation.
+	for _, child := range offspring {
+		child.Chromosome = CloneChromosome(child.Chromosome)
+		child.FitnessValue = nil
+	}
+
+	finish()
+
+	// Frees memory.
+	parents = nil
+	go debug.FreeOSMemory()
+
+	// >> Mutation.
+	finish = engine.startPhase(MutationPhase, generation)
+
+	if engine.Operators.Mutation != nil {
+		for j := 0; j < offspringSize; j++ {
+			engine.Operators.Mutation(randoms[j/2], offspring[j])
+		}
+	}
+
+	finish()
+
+	// Sets the id.
+	nextPopulation := append(elites, offspring...)
+	for j := int64(0); j < int64(populationSize); j++ {
+		nextPopulation[j].Id = (generation+1)*int64(populationSize) + j
+	}
+
+	return nextPopulation
+}
+
+// Runs the generations and returns the evaluated final population.
+func (engine *Engine) Run() ([]*Individual, error) {
+	population := engine.Initialize()
+
+	var err error
+	generation := int64(0)
+	for ; generation < engine.GenerationsNumber; generation++ {
+		// Frees memory.
+		go debug.FreeOSMemory()
+
+		log.Infof("Started generation %v", generation)
+		generationStartTime := time.Now()
+
+		population, err = engine.Evaluate(population, generation, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if engine.Migrator != nil {
+			population, err = engine.Migrator.Migrate(population, generation)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		population = engine.Breed(population, generation)
+
+		log.Infof("Finished generation %v", generation)
+		engine.phaseFinished(GenerationPhase, generation, time.Since(generationStartTime))
+	}
+
+	// Frees memory.
+	go debug.FreeOSMemory()
+
+	return engine.Evaluate(population, generation, true)
+}
+
+// Computes the best, the worst and the average fitness value of an evaluated population.
+func Statistics(population []*Individual, minimization bool) (best *Individual, worst *Individual, average Float64FitnessValue) {
+	populationCopy := make(SortByMinFitnessValueIndividuals, len(population))
+	copy(populationCopy, population)
+	sort.Sort(populationCopy)
+
+	if minimization {
+		best = populationCopy[0]
+		worst = populationCopy[len(populationCopy)-1]
+	} else {
+		best = populationCopy[len(populationCopy)-1]
+		worst = populationCopy[0]
+	}
+
+	fitnessValueSum := 0.0
+	for _, individual := range populationCopy {
+		fitnessValueSum += FitnessValueToFloat64(individual.FitnessValue)
+	}
+	average = Float64FitnessValue(fitnessValueSum / float64(len(populationCopy)))
+
+	return best, worst, average
+}
+
+// Converts a fitness value of the built-in types to float64.
+func FitnessValueToFloat64(fitnessValue FitnessValue) float64 {
+	switch value := fitnessValue.(type) {
+	case ByteFitnessValue:
+		return float64(value)
+	case IntFitnessValue:
+		return float64(value)
+	case Int64FitnessValue:
+		return float64(value)
+	case Float32FitnessValue:
+		return float64(value)
+	case Float64FitnessValue:
+		return float64(value)
+	}
+	return 0.0
+}
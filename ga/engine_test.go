@@ -0,0 +1,126 @@
+package ga
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Counts the evaluated individuals.
+type countingEvaluator struct {
+	SequentialEvaluator
+	evaluations int
+}
+
+func (evaluator *countingEvaluator) Evaluate(population []*Individual) ([]*Individual, error) {
+	evaluator.evaluations += len(population)
+	return evaluator.SequentialEvaluator.Evaluate(population)
+}
+
+func newTestEngine(problem Problem, evaluator Evaluator) *Engine {
+	return &Engine{
+		PopulationSize:    20,
+		GenerationsNumber: 30,
+		Minimization:      problem.Minimization(),
+		Operators:         ProblemOperators(problem, 2, 1.0, 0.1),
+		Evaluator:         evaluator,
+	}
+}
+
+// Fails every evaluation.
+type failingEvaluator struct{}
+
+func (failingEvaluator) Evaluate(population []*Individual) ([]*Individual, error) {
+	return nil, errors.New("evaluation failed")
+}
+
+func TestEngineEvaluationError(t *testing.T) {
+	problem, _ := NewProblem("sphere", ProblemParameters{ChromosomeSize: 10})
+	engine := newTestEngine(problem, failingEvaluator{})
+
+	// The evaluation phase finishes even if it fails.
+	var phases []string
+	engine.Hooks.PhaseFinished = func(phase string, generation int64, elapsed time.Duration) {
+		phases = append(phases, phase)
+	}
+
+	if _, err := engine.Run(); err == nil {
+		t.Fatal("expected an evaluation error")
+	}
+	if expected := []string{InitializationPhase, FitnessEvaluationPhase}; !reflect.DeepEqual(phases, expected) {
+		t.Errorf("expected phases %v, got %v", expected, phases)
+	}
+}
+
+func TestEngineElitism(t *testing.T) {
+	problem, _ := NewProblem("sphere", ProblemParameters{ChromosomeSize: 10})
+	evaluator := &countingEvaluator{SequentialEvaluator: SequentialEvaluator{FitnessFunction: problem.Evaluate}}
+
+	engine := newTestEngine(problem, evaluator)
+	engine.ElitesNumber = 2
+
+	var bestFitnessValue FitnessValue
+	engine.Hooks.PopulationEvaluated = func(generation int64, population []*Individual, solution bool) {
+		if len(population) != engine.PopulationSize {
+			t.Fatalf("expected %v individuals, got %v", engine.PopulationSize, len(population))
+		}
+		best, _, _ := Statistics(population, true)
+		if bestFitnessValue != nil && bestFitnessValue.Less(best.FitnessValue) {
+			t.Errorf("generation %v: best fitness regressed from %v to %v", generation, bestFitnessValue, best.FitnessValue)
+		}
+		bestFitnessValue = best.FitnessValue
+	}
+
+	if _, err := engine.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The elites are evaluated only once.
+	expected := engine.PopulationSize + int(engine.GenerationsNumber)*(engine.PopulationSize-engine.ElitesNumber)
+	if evaluator.evaluations != expected {
+		t.Errorf("expected %v evaluations, got %v", expected, evaluator.evaluations)
+	}
+}
+
+func TestEngineIds(t *testing.T) {
+	problem, _ := NewProblem("ppeaks", ProblemParameters{ChromosomeSize: 16, PeaksNumber: 4})
+	engine := newTestEngine(problem, &SequentialEvaluator{FitnessFunction: problem.Evaluate})
+	engine.ElitesNumber = 3
+
+	engine.Hooks.PopulationEvaluated = func(generation int64, population []*Individual, solution bool) {
+		for j, individual := range population {
+			expected := generation*int64(engine.PopulationSize) + int64(j)
+			if individual.Id != expected {
+				t.Fatalf("generation %v: expected id %v, got %v", generation, expected, individual.Id)
+			}
+			if individual.Generation != generation {
+				t.Fatalf("expected generation %v, got %v", generation, individual.Generation)
+			}
+		}
+	}
+
+	if _, err := engine.Run(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngineSeed(t *testing.T) {
+	problem, _ := NewProblem("sphere", ProblemParameters{ChromosomeSize: 10})
+	run := func(seed int64) []*Individual {
+		engine := newTestEngine(problem, &SequentialEvaluator{FitnessFunction: problem.Evaluate})
+		engine.Seed = seed
+		population, err := engine.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return population
+	}
+
+	if !reflect.DeepEqual(run(1), run(1)) {
+		t.Error("the runs with the same seed have to give the same population")
+	}
+	if reflect.DeepEqual(run(1), run(2)) {
+		t.Error("the runs with different seeds have to give different populations")
+	}
+}
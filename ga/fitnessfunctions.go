@@ -4,6 +4,8 @@ import (
 	"math"
 	"time"
 
+	"github.com/pasqualesalza/amqpga/ga/data/sat"
+	"github.com/pasqualesalza/amqpga/ga/data/tsp"
 	"github.com/pasqualesalza/amqpga/util"
 )
 
@@ -23,8 +25,74 @@ const (
 	RosenbrockFunctionMinBound = -2.048
 	RosenbrockFunctionMaxBound = 2.048
 
+	GriewankFunctionMinBound = -600.0
+	GriewankFunctionMaxBound = 600.0
+
+	LevyFunctionMinBound = -10.0
+	LevyFunctionMaxBound = 10.0
+
+	ZakharovFunctionMinBound = -5.0
+	ZakharovFunctionMaxBound = 10.0
+
+	StyblinskiTangFunctionMinBound = -5.0
+	StyblinskiTangFunctionMaxBound = 5.0
+
+	MichalewiczFunctionMinBound = 0.0
+	MichalewiczFunctionMaxBound = math.Pi
+
+	WeierstrassFunctionMinBound = -0.5
+	WeierstrassFunctionMaxBound = 0.5
+
+	SchafferF6FunctionMinBound = -100.0
+	SchafferF6FunctionMaxBound = 100.0
+
+	SchafferF7FunctionMinBound = -100.0
+	SchafferF7FunctionMaxBound = 100.0
+
+	BentCigarFunctionMinBound = -100.0
+	BentCigarFunctionMaxBound = 100.0
+
+	KatsuuraFunctionMinBound = -100.0
+	KatsuuraFunctionMaxBound = 100.0
+
+	HappyCatFunctionMinBound = -2.0
+	HappyCatFunctionMaxBound = 2.0
+
 	PPeaksFunctionMinBound = 0
 	PPeaksFunctionMaxBound = 1
+
+	BinaryFunctionMinBound = 0
+	BinaryFunctionMaxBound = 1
+)
+
+// The gene values of the global optima of the continuous functions, the same for
+// every gene.
+const (
+	SphereFunctionOptimum     = 0.0
+	RastriginFunctionOptimum  = 0.0
+	AckleyFunctionOptimum     = 0.0
+	SchwefelFunctionOptimum   = 420.9687462275036
+	RosenbrockFunctionOptimum = 1.0
+
+	GriewankFunctionOptimum       = 0.0
+	LevyFunctionOptimum           = 1.0
+	ZakharovFunctionOptimum       = 0.0
+	StyblinskiTangFunctionOptimum = -2.903534027771178
+	WeierstrassFunctionOptimum    = 0.0
+	SchafferF6FunctionOptimum     = 0.0
+	SchafferF7FunctionOptimum     = 0.0
+	BentCigarFunctionOptimum      = 0.0
+	KatsuuraFunctionOptimum       = 0.0
+	HappyCatFunctionOptimum       = -1.0
+)
+
+// The parameters of the Michalewicz and Weierstrass functions.
+const (
+	MichalewiczFunctionSteepness = 10.0
+
+	WeierstrassFunctionA    = 0.5
+	WeierstrassFunctionB    = 3.0
+	WeierstrassFunctionKMax = 20
 )
 
 func SphereFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
@@ -84,6 +152,32 @@ func SchwefelFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64Fi
 	return Float64FitnessValue(result)
 }
 
+// The Schwefel function of the CEC benchmarks, equal to the Schwefel function
+// within its bounds. Out of them every value is folded back into the bounds and
+// penalized by its squared distance from them, so that the optimum stays the
+// global one when the variables are shifted or rotated out of the bounds.
+func ModifiedSchwefelFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
+	result := 0.0
+
+	sum := 0.0
+	for _, x := range vector {
+		switch {
+		case x > SchwefelFunctionMaxBound:
+			folded := SchwefelFunctionMaxBound - math.Mod(x, SchwefelFunctionMaxBound)
+			sum += -folded*math.Sin(math.Sqrt(math.Abs(folded))) + math.Pow(x-SchwefelFunctionMaxBound, 2.0)/(10000.0*float64(len(vector)))
+		case x < SchwefelFunctionMinBound:
+			folded := math.Mod(math.Abs(x), SchwefelFunctionMaxBound) - SchwefelFunctionMaxBound
+			sum += -folded*math.Sin(math.Sqrt(math.Abs(folded))) + math.Pow(x-SchwefelFunctionMinBound, 2.0)/(10000.0*float64(len(vector)))
+		default:
+			sum += -x * math.Sin(math.Sqrt(math.Abs(x)))
+		}
+	}
+
+	result += sum
+
+	return Float64FitnessValue(result)
+}
+
 func RosenbrockFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
 	result := 0.0
 
@@ -97,6 +191,186 @@ func RosenbrockFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64
 	return Float64FitnessValue(result)
 }
 
+func GriewankFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
+	result := 1.0
+
+	sum := 0.0
+	for _, x := range vector {
+		sum += math.Pow(x, 2.0) / 4000.0
+	}
+
+	product := 1.0
+	for i, x := range vector {
+		product *= math.Cos(x / math.Sqrt(float64(i+1)))
+	}
+
+	result += sum - product
+
+	return Float64FitnessValue(result)
+}
+
+func LevyFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
+	w := make([]float64, len(vector))
+	for i, x := range vector {
+		w[i] = 1.0 + (x-1.0)/4.0
+	}
+	n := len(w) - 1
+
+	result := math.Pow(math.Sin(math.Pi*w[0]), 2.0)
+
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		sum += math.Pow(w[i]-1.0, 2.0) * (1.0 + 10.0*math.Pow(math.Sin(math.Pi*w[i]+1.0), 2.0))
+	}
+
+	result += sum + math.Pow(w[n]-1.0, 2.0)*(1.0+math.Pow(math.Sin(2.0*math.Pi*w[n]), 2.0))
+
+	return Float64FitnessValue(result)
+}
+
+func ZakharovFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
+	result := 0.0
+
+	sum1 := 0.0
+	for _, x := range vector {
+		sum1 += math.Pow(x, 2.0)
+	}
+
+	sum2 := 0.0
+	for i, x := range vector {
+		sum2 += 0.5 * float64(i+1) * x
+	}
+
+	result += sum1 + math.Pow(sum2, 2.0) + math.Pow(sum2, 4.0)
+
+	return Float64FitnessValue(result)
+}
+
+func StyblinskiTangFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
+	result := 0.0
+
+	sum := 0.0
+	for _, x := range vector {
+		sum += math.Pow(x, 4.0) - 16.0*math.Pow(x, 2.0) + 5.0*x
+	}
+
+	result += sum / 2.0
+
+	return Float64FitnessValue(result)
+}
+
+// The optimum of the Michalewicz function has different values for every gene,
+// e.g. -1.8013 at (2.20, 1.57) with two genes.
+func MichalewiczFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
+	result := 0.0
+
+	sum := 0.0
+	for i, x := range vector {
+		sum += math.Sin(x) * math.Pow(math.Sin(float64(i+1)*math.Pow(x, 2.0)/math.Pi), 2.0*MichalewiczFunctionSteepness)
+	}
+
+	result -= sum
+
+	return Float64FitnessValue(result)
+}
+
+func WeierstrassFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
+	result := 0.0
+
+	sum1 := 0.0
+	for _, x := range vector {
+		for k := 0; k <= WeierstrassFunctionKMax; k++ {
+			sum1 += math.Pow(WeierstrassFunctionA, float64(k)) * math.Cos(2.0*math.Pi*math.Pow(WeierstrassFunctionB, float64(k))*(x+0.5))
+		}
+	}
+
+	sum2 := 0.0
+	for k := 0; k <= WeierstrassFunctionKMax; k++ {
+		sum2 += math.Pow(WeierstrassFunctionA, float64(k)) * math.Cos(math.Pi*math.Pow(WeierstrassFunctionB, float64(k)))
+	}
+
+	result += sum1 - float64(len(vector))*sum2
+
+	return Float64FitnessValue(result)
+}
+
+// The expanded Schaffer F6 function, summed over the consecutive genes and the
+// last and first genes.
+func SchafferF6FunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
+	result := 0.0
+
+	sum := 0.0
+	for i, x := range vector {
+		squares := math.Pow(x, 2.0) + math.Pow(vector[(i+1)%len(vector)], 2.0)
+		sum += 0.5 + (math.Pow(math.Sin(math.Sqrt(squares)), 2.0)-0.5)/math.Pow(1.0+0.001*squares, 2.0)
+	}
+
+	result += sum
+
+	return Float64FitnessValue(result)
+}
+
+// The Schaffer F7 function, defined for at least two genes.
+func SchafferF7FunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
+	result := 0.0
+
+	sum := 0.0
+	for i := 0; i < len(vector)-1; i++ {
+		s := math.Sqrt(math.Pow(vector[i], 2.0) + math.Pow(vector[i+1], 2.0))
+		sum += math.Sqrt(s) + math.Sqrt(s)*math.Pow(math.Sin(50.0*math.Pow(s, 0.2)), 2.0)
+	}
+
+	result += math.Pow(sum/float64(len(vector)-1), 2.0)
+
+	return Float64FitnessValue(result)
+}
+
+func BentCigarFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
+	result := math.Pow(vector[0], 2.0)
+
+	sum := 0.0
+	for _, x := range vector[1:] {
+		sum += math.Pow(x, 2.0)
+	}
+
+	result += 1e6 * sum
+
+	return Float64FitnessValue(result)
+}
+
+func KatsuuraFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
+	n := float64(len(vector))
+
+	product := 1.0
+	for i, x := range vector {
+		sum := 0.0
+		for j := 1; j <= 32; j++ {
+			power := math.Pow(2.0, float64(j))
+			sum += math.Abs(power*x-math.Round(power*x)) / power
+		}
+		product *= math.Pow(1.0+float64(i+1)*sum, 10.0/math.Pow(n, 1.2))
+	}
+
+	result := 10.0 / math.Pow(n, 2.0) * (product - 1.0)
+
+	return Float64FitnessValue(result)
+}
+
+func HappyCatFunctionFitnessEvaluation(vector Float64VectorChromosome) Float64FitnessValue {
+	n := float64(len(vector))
+
+	squares := 0.0
+	sum := 0.0
+	for _, x := range vector {
+		squares += math.Pow(x, 2.0)
+		sum += x
+	}
+
+	result := math.Pow(math.Abs(squares-n), 0.25) + (0.5*squares+sum)/n + 0.5
+
+	return Float64FitnessValue(result)
+}
+
 func ShiftFloat64Vector(vector []float64, optimumDecisionVector []float64) []float64 {
 	shiftedVector := make([]float64, len(vector))
 	for i := 0; i < len(vector); i++ {
@@ -137,9 +411,9 @@ func PPeaksFitnessFunction(vector ByteVectorChromosome, peaks []ByteVectorChromo
 func EuclideanDistanceFitnessFunction(tour IntVectorChromosome, nodes [][2]int) IntFitnessValue {
 	distance := 0
 
-	for i := 1; i < len(nodes)-1; i++ {
+	for i := 0; i < len(tour); i++ {
 		node1 := nodes[tour[i]]
-		node2 := nodes[tour[i+1]]
+		node2 := nodes[tour[(i+1)%len(tour)]]
 
 		distance += util.EuclideanDistance(node1, node2)
 	}
@@ -147,38 +421,255 @@ func EuclideanDistanceFitnessFunction(tour IntVectorChromosome, nodes [][2]int)
 	return IntFitnessValue(distance)
 }
 
-func MaxMakespanFitnessFunction(schedule IntVectorChromosome, instance [][][2]int) IntFitnessValue {
-	numberOfJobs := len(instance)
-	numberOfMachines := len(instance[0])
+// The P-Peaks function of bit vectors, giving the same values as the byte
+// version.
+func BitPPeaksFitnessFunction(vector BitVectorChromosome, peaks []BitVectorChromosome, size int) Float64FitnessValue {
+	n := size
+	p := len(peaks)
 
-	currentOperations := make([]int, numberOfJobs)
+	max := n - util.BitHamming(vector, peaks[0])
 
-	for i := 0; i < numberOfJobs; i++ {
-		currentOperations[i] = 0
+	for i := 1; i < p; i++ {
+		value := n - util.BitHamming(vector, peaks[i])
+		if value > max {
+			max = value
+		}
 	}
 
-	currentMachine := 0
-	currentMakespan := 0
-	maxMakespan := 0
+	result := float64(max) / float64(n)
+
+	return Float64FitnessValue(result)
+}
+
+func OneMaxFitnessFunction(vector ByteVectorChromosome) Float64FitnessValue {
+	result := 0.0
+
+	for _, x := range vector {
+		result += float64(x)
+	}
+
+	return Float64FitnessValue(result)
+}
+
+// The deceptive trap function of blocks of consecutive genes, whose value
+// decreases with the ones in a block unless all of them are ones.
+func TrapFitnessFunction(vector ByteVectorChromosome, blockSize int) Float64FitnessValue {
+	result := 0.0
+
+	for i := 0; i+blockSize <= len(vector); i += blockSize {
+		ones := 0
+		for _, x := range vector[i : i+blockSize] {
+			ones += int(x)
+		}
+		if ones == blockSize {
+			result += float64(blockSize)
+		} else {
+			result += float64(blockSize - 1 - ones)
+		}
+	}
+
+	return Float64FitnessValue(result)
+}
+
+const MMDPBlockSize = 6
+
+// The values of a block of the massively multimodal deceptive problem, by the
+// number of its ones.
+var mmdpBlockValues = [MMDPBlockSize + 1]float64{1.0, 0.0, 0.360384, 0.640576, 0.360384, 0.0, 1.0}
+
+// The massively multimodal deceptive problem, with blocks of six consecutive
+// genes whose optima have either zero or six ones.
+func MMDPFitnessFunction(vector ByteVectorChromosome) Float64FitnessValue {
+	result := 0.0
+
+	for i := 0; i+MMDPBlockSize <= len(vector); i += MMDPBlockSize {
+		ones := 0
+		for _, x := range vector[i : i+MMDPBlockSize] {
+			ones += int(x)
+		}
+		result += mmdpBlockValues[ones]
+	}
+
+	return Float64FitnessValue(result)
+}
+
+// The mean of the contributions of the genes, each depending on the gene and
+// on its neighbours.
+func NKLandscapeFitnessFunction(vector ByteVectorChromosome, landscape *NKLandscape) Float64FitnessValue {
+	result := 0.0
+
+	sum := 0.0
+	for i, neighbours := range landscape.Neighbours {
+		index := int(vector[i])
+		for _, neighbour := range neighbours {
+			index = index<<1 | int(vector[neighbour])
+		}
+		sum += landscape.Contributions[i][index]
+	}
+
+	result += sum / float64(len(vector))
+
+	return Float64FitnessValue(result)
+}
+
+// The number of satisfied clauses, where the gene of a variable is its value.
+func MAXSATFitnessFunction(vector ByteVectorChromosome, formula *sat.Formula) Float64FitnessValue {
+	result := 0.0
 
-	for i := 0; i < len(schedule); i++ {
-		job := schedule[i]
+	for _, clause := range formula.Clauses {
+		for _, literal := range clause {
+			if (literal > 0 && vector[literal-1] == 1) || (literal < 0 && vector[-literal-1] == 0) {
+				result += 1.0
+				break
+			}
+		}
+	}
 
-		currentOperation := currentOperations[job]
+	return Float64FitnessValue(result)
+}
 
-		currentMakespan += instance[job][currentOperation][1]
+// The value of the items in the knapsack, minus the excess weight multiplied by
+// the penalty coefficient.
+func KnapsackPenaltyFitnessFunction(vector ByteVectorChromosome, instance *KnapsackInstance, penaltyCoefficient float64) Float64FitnessValue {
+	value, weight := instance.Fill(vector)
+
+	result := float64(value)
+	if weight > instance.Capacity {
+		result -= penaltyCoefficient * float64(weight-instance.Capacity)
+	}
+
+	return Float64FitnessValue(result)
+}
+
+// The value of the items in the knapsack, after removing the items in the
+// repair order until they fit. The chromosome is left as it is.
+func KnapsackRepairFitnessFunction(vector ByteVectorChromosome, instance *KnapsackInstance, repairOrder []int) Float64FitnessValue {
+	value, weight := instance.Fill(vector)
+
+	for _, item := range repairOrder {
+		if weight <= instance.Capacity {
+			break
+		}
+		if vector[item] == 1 {
+			value -= instance.Values[item]
+			weight -= instance.Weights[item]
+		}
+	}
+
+	return Float64FitnessValue(value)
+}
+
+func TourLengthFitnessFunction(tour IntVectorChromosome, instance *tsp.Instance) IntFitnessValue {
+	length := 0
+
+	for i := 0; i < len(tour); i++ {
+		length += instance.Distance(tour[i], tour[(i+1)%len(tour)])
+	}
+
+	return IntFitnessValue(length)
+}
+
+// Decodes an operation-based schedule into a semi-active schedule and returns
+// its makespan. The schedule lists a job for each operation, and the k-th
+// occurrence of a job refers to its k-th operation. Each operation of the
+// instance is a (machine, processing time) pair.
+func MaxMakespanFitnessFunction(schedule IntVectorChromosome, instance [][][2]int) IntFitnessValue {
+	numberOfMachines := 0
+	for _, operations := range instance {
+		for _, operation := range operations {
+			if operation[0]+1 > numberOfMachines {
+				numberOfMachines = operation[0] + 1
+			}
+		}
+	}
+
+	currentOperations := make([]int, len(instance))
+	jobsCompletionTimes := make([]int, len(instance))
+	machinesCompletionTimes := make([]int, numberOfMachines)
+
+	maxMakespan := 0
+	for _, job := range schedule {
+		operation := instance[job][currentOperations[job]]
+		machine := operation[0]
+
+		// The operation starts when both its job and its machine are available.
+		startTime := jobsCompletionTimes[job]
+		if machinesCompletionTimes[machine] > startTime {
+			startTime = machinesCompletionTimes[machine]
+		}
+		completionTime := startTime + operation[1]
+
+		jobsCompletionTimes[job] = completionTime
+		machinesCompletionTimes[machine] = completionTime
 		currentOperations[job]++
 
-		if (i+1)%numberOfMachines == 0 {
-			if currentMakespan > maxMakespan {
-				maxMakespan = currentMakespan
+		if completionTime > maxMakespan {
+			maxMakespan = completionTime
+		}
+	}
+
+	return IntFitnessValue(maxMakespan)
+}
+
+// Decodes an operation-based schedule into an active schedule and returns its
+// makespan. Each operation is inserted in the earliest idle interval of its
+// machine that can hold it, otherwise it is appended as in the semi-active
+// decoding.
+func ActiveMakespanFitnessFunction(schedule IntVectorChromosome, instance [][][2]int) IntFitnessValue {
+	numberOfMachines := 0
+	for _, operations := range instance {
+		for _, operation := range operations {
+			if operation[0]+1 > numberOfMachines {
+				numberOfMachines = operation[0] + 1
 			}
+		}
+	}
+
+	currentOperations := make([]int, len(instance))
+	jobsCompletionTimes := make([]int, len(instance))
 
-			currentMachine++
-			currentMakespan = 0
+	// The busy intervals of each machine, sorted by start time.
+	machinesIntervals := make([][][2]int, numberOfMachines)
+
+	maxMakespan := 0
+	for _, job := range schedule {
+		operation := instance[job][currentOperations[job]]
+		machine := operation[0]
+		duration := operation[1]
+		intervals := machinesIntervals[machine]
+
+		// Finds the first idle interval that can hold the operation.
+		position := len(intervals)
+		startTime := jobsCompletionTimes[job]
+		idleStartTime := 0
+		for i, interval := range intervals {
+			candidateStartTime := jobsCompletionTimes[job]
+			if idleStartTime > candidateStartTime {
+				candidateStartTime = idleStartTime
+			}
+			if candidateStartTime+duration <= interval[0] {
+				position = i
+				startTime = candidateStartTime
+				break
+			}
+			idleStartTime = interval[1]
 		}
+		if position == len(intervals) && idleStartTime > startTime {
+			startTime = idleStartTime
+		}
+		completionTime := startTime + duration
+
+		intervals = append(intervals, [2]int{})
+		copy(intervals[position+1:], intervals[position:])
+		intervals[position] = [2]int{startTime, completionTime}
+		machinesIntervals[machine] = intervals
 
-		i++
+		jobsCompletionTimes[job] = completionTime
+		currentOperations[job]++
+
+		if completionTime > maxMakespan {
+			maxMakespan = completionTime
+		}
 	}
 
 	return IntFitnessValue(maxMakespan)
@@ -1,18 +1,22 @@
 package ga
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/pasqualesalza/amqpga/ga/data/jss"
+	"github.com/pasqualesalza/amqpga/ga/data/sat"
 	"github.com/pasqualesalza/amqpga/ga/data/tsp"
 )
 
 // Utility function.
 func benchmarkFloat64FitnessEvaluation(fitnessFunction func(vector Float64VectorChromosome) Float64FitnessValue, chromosomeSize int, minBound float64, maxBound float64, b *testing.B) {
+	random := rand.New(rand.NewSource(1))
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
-		chromosome := Float64VectorChromosomeInitialization(chromosomeSize, minBound, maxBound)
+		chromosome := Float64VectorChromosomeInitialization(random, chromosomeSize, minBound, maxBound)
 		b.StartTimer()
 		fitnessFunction(chromosome)
 	}
@@ -126,6 +130,145 @@ func BenchmarkYN4JSSFitnessEvaluation(b *testing.B) {
 	benchmarkJSSFitnessEvaluation(jss.YN4JSS, b)
 }
 
+// Global optima of the continuous functions.
+func uniformFloat64Vector(size int, value float64) Float64VectorChromosome {
+	vector := make(Float64VectorChromosome, size)
+	for i := range vector {
+		vector[i] = value
+	}
+	return vector
+}
+
+func TestContinuousFunctionsOptimum(t *testing.T) {
+	tests := []struct {
+		name            string
+		fitnessFunction func(vector Float64VectorChromosome) Float64FitnessValue
+		vector          Float64VectorChromosome
+		expected        float64
+	}{
+		{"sphere", SphereFunctionFitnessEvaluation, uniformFloat64Vector(10, SphereFunctionOptimum), 0},
+		{"rastrigin", RastriginFunctionFitnessEvaluation, uniformFloat64Vector(10, RastriginFunctionOptimum), 0},
+		{"ackley", AckleyFunctionFitnessEvaluation, uniformFloat64Vector(10, AckleyFunctionOptimum), 0},
+		{"schwefel", SchwefelFunctionFitnessEvaluation, uniformFloat64Vector(10, SchwefelFunctionOptimum), -4189.828872724338},
+		{"modified-schwefel", ModifiedSchwefelFunctionFitnessEvaluation, uniformFloat64Vector(10, SchwefelFunctionOptimum), -4189.828872724338},
+		{"rosenbrock", RosenbrockFunctionFitnessEvaluation, uniformFloat64Vector(10, RosenbrockFunctionOptimum), 0},
+		{"griewank", GriewankFunctionFitnessEvaluation, uniformFloat64Vector(10, GriewankFunctionOptimum), 0},
+		{"levy", LevyFunctionFitnessEvaluation, uniformFloat64Vector(10, LevyFunctionOptimum), 0},
+		{"zakharov", ZakharovFunctionFitnessEvaluation, uniformFloat64Vector(10, ZakharovFunctionOptimum), 0},
+		{"styblinski-tang", StyblinskiTangFunctionFitnessEvaluation, uniformFloat64Vector(10, StyblinskiTangFunctionOptimum), -391.6616570377142},
+		{"michalewicz", MichalewiczFunctionFitnessEvaluation, Float64VectorChromosome{2.20290552014618, 1.57079632677565}, -1.80130341009855},
+		{"weierstrass", WeierstrassFunctionFitnessEvaluation, uniformFloat64Vector(10, WeierstrassFunctionOptimum), 0},
+		{"schaffer-f6", SchafferF6FunctionFitnessEvaluation, uniformFloat64Vector(10, SchafferF6FunctionOptimum), 0},
+		{"schaffer-f7", SchafferF7FunctionFitnessEvaluation, uniformFloat64Vector(10, SchafferF7FunctionOptimum), 0},
+		{"bent-cigar", BentCigarFunctionFitnessEvaluation, uniformFloat64Vector(10, BentCigarFunctionOptimum), 0},
+		{"katsuura", KatsuuraFunctionFitnessEvaluation, uniformFloat64Vector(10, KatsuuraFunctionOptimum), 0},
+		{"happy-cat", HappyCatFunctionFitnessEvaluation, uniformFloat64Vector(10, HappyCatFunctionOptimum), 0},
+	}
+
+	random := rand.New(rand.NewSource(1))
+	for _, test := range tests {
+		optimum := float64(test.fitnessFunction(test.vector))
+		if math.Abs(optimum-test.expected) > 1e-6 {
+			t.Errorf("%v: expected %v at the optimum, got %v", test.name, test.expected, optimum)
+		}
+
+		// No point near the optimum is better.
+		for i := 0; i < 100; i++ {
+			neighbour := make(Float64VectorChromosome, len(test.vector))
+			for j, x := range test.vector {
+				neighbour[j] = x + (random.Float64()-0.5)*1e-3
+			}
+			if fitness := float64(test.fitnessFunction(neighbour)); fitness < optimum-1e-9 {
+				t.Errorf("%v: expected a fitness value over %v, got %v at %v", test.name, optimum, fitness, neighbour)
+			}
+		}
+	}
+}
+
+// Binary functions.
+func TestOneMaxFitnessFunction(t *testing.T) {
+	if fitness := OneMaxFitnessFunction(ByteVectorChromosome{1, 0, 1, 1}); fitness != 3 {
+		t.Errorf("expected 3, got %v", fitness)
+	}
+}
+
+func TestTrapFitnessFunction(t *testing.T) {
+	// The optimum block, a deceptive block and the deceptive attractor.
+	if fitness := TrapFitnessFunction(ByteVectorChromosome{1, 1, 1, 1, 1, 1, 0, 0, 0, 0, 0, 0}, 4); fitness != 4+1+3 {
+		t.Errorf("expected 8, got %v", fitness)
+	}
+}
+
+func TestMMDPFitnessFunction(t *testing.T) {
+	if fitness := MMDPFitnessFunction(ByteVectorChromosome{1, 1, 1, 1, 1, 1, 0, 0, 0, 0, 0, 0}); fitness != 2 {
+		t.Errorf("expected 2, got %v", fitness)
+	}
+	if fitness := MMDPFitnessFunction(ByteVectorChromosome{1, 1, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0}); fitness != 0.640576 {
+		t.Errorf("expected 0.640576, got %v", fitness)
+	}
+}
+
+func TestNKLandscapeFitnessFunction(t *testing.T) {
+	landscape := &NKLandscape{
+		Neighbours:    [][]int{{1}, {0}},
+		Contributions: [][]float64{{0.1, 0.2, 0.3, 0.4}, {0.5, 0.6, 0.7, 0.8}},
+	}
+	// The gene is the high bit of the index and its neighbour the low one.
+	if fitness := NKLandscapeFitnessFunction(ByteVectorChromosome{1, 0}, landscape); math.Abs(float64(fitness)-(0.3+0.6)/2) > 1e-9 {
+		t.Errorf("expected 0.45, got %v", fitness)
+	}
+}
+
+func TestMAXSATFitnessFunction(t *testing.T) {
+	formula := &sat.Formula{Variables: 3, Clauses: [][]int{{1, -2}, {2, 3}, {-1}, {-3}}}
+	if fitness := MAXSATFitnessFunction(ByteVectorChromosome{0, 1, 0}, formula); fitness != 3 {
+		t.Errorf("expected 3, got %v", fitness)
+	}
+}
+
+func TestKnapsackFitnessFunctions(t *testing.T) {
+	instance := &KnapsackInstance{Weights: []int{5, 4, 3}, Values: []int{10, 4, 6}, Capacity: 8}
+	vector := ByteVectorChromosome{1, 1, 1}
+
+	// The second item has the lowest value per weight unit.
+	if fitness := KnapsackRepairFitnessFunction(vector, instance, []int{1, 2, 0}); fitness != 16 {
+		t.Errorf("expected 16, got %v", fitness)
+	}
+	if vector[1] != 1 {
+		t.Error("the repair has to leave the chromosome as it is")
+	}
+	if fitness := KnapsackPenaltyFitnessFunction(vector, instance, 2); fitness != 20-2*4 {
+		t.Errorf("expected 12, got %v", fitness)
+	}
+	if fitness := KnapsackPenaltyFitnessFunction(ByteVectorChromosome{1, 0, 1}, instance, 2); fitness != 16 {
+		t.Errorf("expected 16, got %v", fitness)
+	}
+}
+
+// JSS decoders.
+var testJSSInstance = [][][2]int{
+	{{0, 3}, {1, 2}},
+	{{1, 2}, {0, 4}},
+}
+
+func TestMaxMakespanFitnessFunction(t *testing.T) {
+	if makespan := MaxMakespanFitnessFunction(IntVectorChromosome{0, 1, 0, 1}, testJSSInstance); makespan != 7 {
+		t.Errorf("expected 7, got %v", makespan)
+	}
+	if makespan := MaxMakespanFitnessFunction(IntVectorChromosome{0, 0, 1, 1}, testJSSInstance); makespan != 11 {
+		t.Errorf("expected 11, got %v", makespan)
+	}
+}
+
+func TestActiveMakespanFitnessFunction(t *testing.T) {
+	if makespan := ActiveMakespanFitnessFunction(IntVectorChromosome{0, 1, 0, 1}, testJSSInstance); makespan != 7 {
+		t.Errorf("expected 7, got %v", makespan)
+	}
+	if makespan := ActiveMakespanFitnessFunction(IntVectorChromosome{0, 0, 1, 1}, testJSSInstance); makespan != 7 {
+		t.Errorf("expected 7, got %v", makespan)
+	}
+}
+
 // Sleep utility function.
 func benchmarkSleepFitnessEvaluation(duration time.Duration, b *testing.B) {
 	for i := 0; i < b.N; i++ {
@@ -12,9 +12,9 @@ const (
 	BLXAlpha = 0.5
 )
 
-func TournamentSelection(individuals []*Individual, size int, minimization bool) *Individual {
+func TournamentSelection(random *rand.Rand, individuals []*Individual, size int, minimization bool) *Individual {
 	// Select individuals for tournament.
-	randomSelectionIndices := rand.Perm(len(individuals))
+	randomSelectionIndices := random.Perm(len(individuals))
 	randomSelection := make([]*Individual, size)
 	for i := 0; i < size; i++ {
 		randomSelection[i] = individuals[randomSelectionIndices[i]]
@@ -41,7 +41,7 @@ func TournamentSelection(individuals []*Individual, size int, minimization bool)
 	return nil
 }
 
-func RouletteWheelSelection(individuals []*Individual, minimization bool) *Individual {
+func RouletteWheelSelection(random *rand.Rand, individuals []*Individual, minimization bool) *Individual {
 	// Extracts the weights.
 	weights := make([]float64, len(individuals))
 	for i, individual := range individuals {
@@ -85,7 +85,7 @@ func RouletteWheelSelection(individuals []*Individual, minimization bool) *Indiv
 	}
 
 	// Gets random value.
-	value := rand.Float64() * weightSum
+	value := random.Float64() * weightSum
 
 	// Locates the random value based on the weights
 	for i := 0; i < len(weights); i++ {
@@ -98,8 +98,8 @@ func RouletteWheelSelection(individuals []*Individual, minimization bool) *Indiv
 	return individuals[len(individuals)-1]
 }
 
-func BLXCrossover(parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
-	if rand.Float64() <= crossoverRate {
+func BLXCrossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	if random.Float64() <= crossoverRate {
 		parent1Chromosome := parent1.Chromosome.(Float64VectorChromosome)
 		parent2Chromosome := parent2.Chromosome.(Float64VectorChromosome)
 
@@ -117,8 +117,8 @@ func BLXCrossover(parent1, parent2 *Individual, crossoverRate float64) (Individu
 			minBound := x1 - partial
 			maxBound := x2 - partial
 
-			child1Chromosome[i] = util.RandomFloat64InRange(minBound, maxBound)
-			child2Chromosome[i] = util.RandomFloat64InRange(minBound, maxBound)
+			child1Chromosome[i] = util.RandomFloat64InRange(random, minBound, maxBound)
+			child2Chromosome[i] = util.RandomFloat64InRange(random, minBound, maxBound)
 		}
 
 		var child1 Individual
@@ -135,8 +135,8 @@ func BLXCrossover(parent1, parent2 *Individual, crossoverRate float64) (Individu
 	return *parent1, *parent2
 }
 
-func SinglePointCrossover(parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
-	if rand.Float64() <= crossoverRate {
+func SinglePointCrossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	if random.Float64() <= crossoverRate {
 		parent1Chromosome := reflect.ValueOf(parent1.Chromosome)
 		parent2Chromosome := reflect.ValueOf(parent2.Chromosome)
 
@@ -145,7 +145,7 @@ func SinglePointCrossover(parent1, parent2 *Individual, crossoverRate float64) (
 		child1Chromosome := reflect.MakeSlice(chromosomeType, parent1Chromosome.Len(), parent1Chromosome.Len())
 		child2Chromosome := reflect.MakeSlice(chromosomeType, parent1Chromosome.Len(), parent1Chromosome.Len())
 
-		point := rand.Intn(parent1Chromosome.Len()-1) + 1
+		point := random.Intn(parent1Chromosome.Len()-1) + 1
 
 		for i := 0; i < parent1Chromosome.Len(); i++ {
 			if i < point {
@@ -171,8 +171,8 @@ func SinglePointCrossover(parent1, parent2 *Individual, crossoverRate float64) (
 	return *parent1, *parent2
 }
 
-func TwoPointsCrossover(parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
-	if rand.Float64() <= crossoverRate {
+func TwoPointsCrossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	if random.Float64() <= crossoverRate {
 		parent1Chromosome := reflect.ValueOf(parent1.Chromosome)
 		parent2Chromosome := reflect.ValueOf(parent2.Chromosome)
 
@@ -181,8 +181,8 @@ func TwoPointsCrossover(parent1, parent2 *Individual, crossoverRate float64) (In
 		child1Chromosome := reflect.MakeSlice(chromosomeType, parent1Chromosome.Len(), parent1Chromosome.Len())
 		child2Chromosome := reflect.MakeSlice(chromosomeType, parent1Chromosome.Len(), parent1Chromosome.Len())
 
-		point1 := util.RandomIntInRange(1, parent1Chromosome.Len()-2)
-		point2 := util.RandomIntInRange(point1+1, parent1Chromosome.Len()-1)
+		point1 := util.RandomIntInRange(random, 1, parent1Chromosome.Len()-2)
+		point2 := util.RandomIntInRange(random, point1+1, parent1Chromosome.Len()-1)
 
 		for i := 0; i < parent1Chromosome.Len(); i++ {
 			switch {
@@ -212,29 +212,406 @@ func TwoPointsCrossover(parent1, parent2 *Individual, crossoverRate float64) (In
 	return *parent1, *parent2
 }
 
-func Float64RandomMutation(individual *Individual, min float64, max float64, mutationRate float64) {
+// The two points crossover of bit vectors, exchanging the bits between the cut
+// points a word at a time. It draws the same cut points as TwoPointsCrossover on
+// a byte vector of the same size.
+func BitTwoPointsCrossover(random *rand.Rand, parent1, parent2 *Individual, size int, crossoverRate float64) (Individual, Individual) {
+	if random.Float64() <= crossoverRate {
+		parent1Chromosome := parent1.Chromosome.(BitVectorChromosome)
+		parent2Chromosome := parent2.Chromosome.(BitVectorChromosome)
+
+		child1Chromosome := make(BitVectorChromosome, len(parent1Chromosome))
+		child2Chromosome := make(BitVectorChromosome, len(parent1Chromosome))
+
+		point1 := util.RandomIntInRange(random, 1, size-2)
+		point2 := util.RandomIntInRange(random, point1+1, size-1)
+
+		for i := range parent1Chromosome {
+			mask := bitRangeMask(i, point1, point2)
+			child1Chromosome[i] = parent1Chromosome[i]&^mask | parent2Chromosome[i]&mask
+			child2Chromosome[i] = parent2Chromosome[i]&^mask | parent1Chromosome[i]&mask
+		}
+
+		var child1 Individual
+		child1.Generation = parent1.Generation
+		child1.Chromosome = child1Chromosome
+
+		var child2 Individual
+		child2.Generation = parent2.Generation
+		child2.Chromosome = child2Chromosome
+
+		return child1, child2
+	}
+
+	return *parent1, *parent2
+}
+
+// Returns the mask of the bits of a word in [from, to).
+func bitRangeMask(word int, from, to int) uint64 {
+	first := from - word*64
+	if first < 0 {
+		first = 0
+	}
+	last := to - word*64
+	if last > 64 {
+		last = 64
+	}
+	if first >= last {
+		return 0
+	}
+
+	mask := ^uint64(0) << uint(first)
+	if last < 64 {
+		mask &= 1<<uint(last) - 1
+	}
+	return mask
+}
+
+// The random mutation of bit vectors, drawing the same numbers as
+// ByteRandomMutation between 0 and 1 on a byte vector of the same size.
+func BitRandomMutation(random *rand.Rand, individual *Individual, size int, mutationRate float64) {
+	chromosome := individual.Chromosome.(BitVectorChromosome)
+	for i := 0; i < size; i++ {
+		if random.Float64() <= mutationRate {
+			chromosome.SetBit(i, util.RandomByteInRange(random, 0, 1))
+		}
+	}
+}
+
+func Float64RandomMutation(random *rand.Rand, individual *Individual, min float64, max float64, mutationRate float64) {
 	chromosome := individual.Chromosome.(Float64VectorChromosome)
 	for i := 0; i < len(chromosome); i++ {
-		if rand.Float64() <= mutationRate {
-			chromosome[i] = util.RandomFloat64InRange(min, max)
+		if random.Float64() <= mutationRate {
+			chromosome[i] = util.RandomFloat64InRange(random, min, max)
 		}
 	}
 }
 
-func ByteRandomMutation(individual *Individual, min, max byte, mutationRate float64) {
+func ByteRandomMutation(random *rand.Rand, individual *Individual, min, max byte, mutationRate float64) {
 	chromosome := individual.Chromosome.(ByteVectorChromosome)
 	for i := 0; i < len(chromosome); i++ {
-		if rand.Float64() <= mutationRate {
-			chromosome[i] = util.RandomByteInRange(min, max)
+		if random.Float64() <= mutationRate {
+			chromosome[i] = util.RandomByteInRange(random, min, max)
 		}
 	}
 }
 
-func IntRandomMutation(individual *Individual, min, max int, mutationRate float64) {
+func IntRandomMutation(random *rand.Rand, individual *Individual, min, max int, mutationRate float64) {
 	chromosome := individual.Chromosome.(IntVectorChromosome)
 	for i := 0; i < len(chromosome); i++ {
-		if rand.Float64() <= mutationRate {
-			chromosome[i] = util.RandomIntInRange(min, max)
+		if random.Float64() <= mutationRate {
+			chromosome[i] = util.RandomIntInRange(random, min, max)
+		}
+	}
+}
+
+// Selects two random cut points of a chromosome so that 0 <= point1 < point2 <= size.
+func randomCutPoints(random *rand.Rand, size int) (int, int) {
+	point1 := random.Intn(size)
+	point2 := random.Intn(size)
+	if point1 > point2 {
+		point1, point2 = point2, point1
+	}
+	return point1, point2 + 1
+}
+
+func orderCrossoverChild(parent1Chromosome, parent2Chromosome IntVectorChromosome, point1, point2 int) IntVectorChromosome {
+	size := len(parent1Chromosome)
+	childChromosome := make(IntVectorChromosome, size)
+
+	// Copies the segment of the first parent.
+	used := make([]bool, size)
+	for i := point1; i < point2; i++ {
+		childChromosome[i] = parent1Chromosome[i]
+		used[parent1Chromosome[i]] = true
+	}
+
+	// Fills the remaining genes in the order of the second parent, starting after the segment.
+	position := point2 % size
+	for i := 0; i < size; i++ {
+		gene := parent2Chromosome[(point2+i)%size]
+		if used[gene] {
+			continue
 		}
+		childChromosome[position] = gene
+		used[gene] = true
+		position = (position + 1) % size
 	}
+
+	return childChromosome
+}
+
+// Order crossover (OX) for permutations of [0, n).
+func OrderCrossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	if random.Float64() <= crossoverRate {
+		parent1Chromosome := parent1.Chromosome.(IntVectorChromosome)
+		parent2Chromosome := parent2.Chromosome.(IntVectorChromosome)
+
+		point1, point2 := randomCutPoints(random, len(parent1Chromosome))
+
+		var child1 Individual
+		child1.Generation = parent1.Generation
+		child1.Chromosome = orderCrossoverChild(parent1Chromosome, parent2Chromosome, point1, point2)
+
+		var child2 Individual
+		child2.Generation = parent2.Generation
+		child2.Chromosome = orderCrossoverChild(parent2Chromosome, parent1Chromosome, point1, point2)
+
+		return child1, child2
+	}
+
+	return *parent1, *parent2
+}
+
+func partiallyMappedCrossoverChild(parent1Chromosome, parent2Chromosome IntVectorChromosome, point1, point2 int) IntVectorChromosome {
+	size := len(parent1Chromosome)
+
+	// Starts from the second parent.
+	childChromosome := make(IntVectorChromosome, size)
+	copy(childChromosome, parent2Chromosome)
+	positions := make([]int, size)
+	for i, gene := range childChromosome {
+		positions[gene] = i
+	}
+
+	// Moves the genes of the first parent segment in place, following the mapping.
+	for i := point1; i < point2; i++ {
+		gene := parent1Chromosome[i]
+		j := positions[gene]
+		childChromosome[i], childChromosome[j] = childChromosome[j], childChromosome[i]
+		positions[childChromosome[i]] = i
+		positions[childChromosome[j]] = j
+	}
+
+	return childChromosome
+}
+
+// Partially mapped crossover (PMX) for permutations of [0, n).
+func PartiallyMappedCrossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	if random.Float64() <= crossoverRate {
+		parent1Chromosome := parent1.Chromosome.(IntVectorChromosome)
+		parent2Chromosome := parent2.Chromosome.(IntVectorChromosome)
+
+		point1, point2 := randomCutPoints(random, len(parent1Chromosome))
+
+		var child1 Individual
+		child1.Generation = parent1.Generation
+		child1.Chromosome = partiallyMappedCrossoverChild(parent1Chromosome, parent2Chromosome, point1, point2)
+
+		var child2 Individual
+		child2.Generation = parent2.Generation
+		child2.Chromosome = partiallyMappedCrossoverChild(parent2Chromosome, parent1Chromosome, point1, point2)
+
+		return child1, child2
+	}
+
+	return *parent1, *parent2
+}
+
+// Cycle crossover (CX) for permutations of [0, n).
+func CycleCrossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	if random.Float64() <= crossoverRate {
+		parent1Chromosome := parent1.Chromosome.(IntVectorChromosome)
+		parent2Chromosome := parent2.Chromosome.(IntVectorChromosome)
+		size := len(parent1Chromosome)
+
+		positions := make([]int, size)
+		for i, gene := range parent1Chromosome {
+			positions[gene] = i
+		}
+
+		child1Chromosome := make(IntVectorChromosome, size)
+		child2Chromosome := make(IntVectorChromosome, size)
+
+		// Copies the cycles alternately from the parents.
+		visited := make([]bool, size)
+		cycle := 0
+		for start := 0; start < size; start++ {
+			if visited[start] {
+				continue
+			}
+			for i := start; !visited[i]; i = positions[parent2Chromosome[i]] {
+				visited[i] = true
+				if cycle%2 == 0 {
+					child1Chromosome[i] = parent1Chromosome[i]
+					child2Chromosome[i] = parent2Chromosome[i]
+				} else {
+					child1Chromosome[i] = parent2Chromosome[i]
+					child2Chromosome[i] = parent1Chromosome[i]
+				}
+			}
+			cycle++
+		}
+
+		var child1 Individual
+		child1.Generation = parent1.Generation
+		child1.Chromosome = child1Chromosome
+
+		var child2 Individual
+		child2.Generation = parent2.Generation
+		child2.Chromosome = child2Chromosome
+
+		return child1, child2
+	}
+
+	return *parent1, *parent2
+}
+
+// Swaps each gene with a random one with probability mutationRate.
+func SwapMutation(random *rand.Rand, individual *Individual, mutationRate float64) {
+	chromosome := individual.Chromosome.(IntVectorChromosome)
+	for i := 0; i < len(chromosome); i++ {
+		if random.Float64() <= mutationRate {
+			j := random.Intn(len(chromosome))
+			chromosome[i], chromosome[j] = chromosome[j], chromosome[i]
+		}
+	}
+}
+
+// Reverses the segment between each gene and a random one with probability
+// mutationRate.
+func InversionMutation(random *rand.Rand, individual *Individual, mutationRate float64) {
+	chromosome := individual.Chromosome.(IntVectorChromosome)
+	for i := 0; i < len(chromosome); i++ {
+		if random.Float64() <= mutationRate {
+			j := random.Intn(len(chromosome))
+			point1, point2 := i, j
+			if point1 > point2 {
+				point1, point2 = point2, point1
+			}
+			for ; point1 < point2; point1, point2 = point1+1, point2-1 {
+				chromosome[point1], chromosome[point2] = chromosome[point2], chromosome[point1]
+			}
+		}
+	}
+}
+
+// Moves each gene to a random position with probability mutationRate.
+func InsertionMutation(random *rand.Rand, individual *Individual, mutationRate float64) {
+	chromosome := individual.Chromosome.(IntVectorChromosome)
+	for from := 0; from < len(chromosome); from++ {
+		if random.Float64() <= mutationRate {
+			to := random.Intn(len(chromosome))
+			gene := chromosome[from]
+			if from < to {
+				copy(chromosome[from:to], chromosome[from+1:to+1])
+			} else {
+				copy(chromosome[to+1:from+1], chromosome[to:from])
+			}
+			chromosome[to] = gene
+		}
+	}
+}
+
+func jobOrderCrossoverChild(parent1Chromosome, parent2Chromosome IntVectorChromosome, kept map[int]bool) IntVectorChromosome {
+	childChromosome := make(IntVectorChromosome, len(parent1Chromosome))
+
+	// Fills the other positions with the other jobs in the order of the second parent.
+	j := 0
+	for i, gene := range parent1Chromosome {
+		if kept[gene] {
+			childChromosome[i] = gene
+			continue
+		}
+		for kept[parent2Chromosome[j]] {
+			j++
+		}
+		childChromosome[i] = parent2Chromosome[j]
+		j++
+	}
+
+	return childChromosome
+}
+
+// Job-based order crossover (JOX) for permutations with repetition: the genes of
+// a random subset of jobs keep the positions of a parent, while the other genes
+// follow the order of the other parent.
+func JobOrderCrossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	if random.Float64() <= crossoverRate {
+		parent1Chromosome := parent1.Chromosome.(IntVectorChromosome)
+		parent2Chromosome := parent2.Chromosome.(IntVectorChromosome)
+
+		kept := make(map[int]bool)
+		for _, gene := range parent1Chromosome {
+			if _, ok := kept[gene]; !ok {
+				kept[gene] = random.Intn(2) == 0
+			}
+		}
+
+		var child1 Individual
+		child1.Generation = parent1.Generation
+		child1.Chromosome = jobOrderCrossoverChild(parent1Chromosome, parent2Chromosome, kept)
+
+		var child2 Individual
+		child2.Generation = parent2.Generation
+		child2.Chromosome = jobOrderCrossoverChild(parent2Chromosome, parent1Chromosome, kept)
+
+		return child1, child2
+	}
+
+	return *parent1, *parent2
+}
+
+func precedencePreservativeCrossoverChild(parent1Chromosome, parent2Chromosome IntVectorChromosome, choices []bool) IntVectorChromosome {
+	size := len(parent1Chromosome)
+	childChromosome := make(IntVectorChromosome, 0, size)
+
+	// Picks the leftmost unused gene of the chosen parent and removes its first
+	// unused occurrence from the other parent.
+	used := [2][]bool{make([]bool, size), make([]bool, size)}
+	parents := [2]IntVectorChromosome{parent1Chromosome, parent2Chromosome}
+	positions := [2]int{0, 0}
+	for _, choice := range choices {
+		chosen, other := 0, 1
+		if !choice {
+			chosen, other = 1, 0
+		}
+
+		for used[chosen][positions[chosen]] {
+			positions[chosen]++
+		}
+		gene := parents[chosen][positions[chosen]]
+		used[chosen][positions[chosen]] = true
+		childChromosome = append(childChromosome, gene)
+
+		for i := positions[other]; i < size; i++ {
+			if !used[other][i] && parents[other][i] == gene {
+				used[other][i] = true
+				break
+			}
+		}
+	}
+
+	return childChromosome
+}
+
+// Precedence preservative crossover (PPX) for permutations with repetition: the
+// genes are drawn from the parents following a random choice vector, preserving
+// the relative order of the operations in both parents.
+func PrecedencePreservativeCrossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	if random.Float64() <= crossoverRate {
+		parent1Chromosome := parent1.Chromosome.(IntVectorChromosome)
+		parent2Chromosome := parent2.Chromosome.(IntVectorChromosome)
+
+		choices := make([]bool, len(parent1Chromosome))
+		for i := range choices {
+			choices[i] = random.Intn(2) == 0
+		}
+		complementaryChoices := make([]bool, len(choices))
+		for i, choice := range choices {
+			complementaryChoices[i] = !choice
+		}
+
+		var child1 Individual
+		child1.Generation = parent1.Generation
+		child1.Chromosome = precedencePreservativeCrossoverChild(parent1Chromosome, parent2Chromosome, choices)
+
+		var child2 Individual
+		child2.Generation = parent2.Generation
+		child2.Chromosome = precedencePreservativeCrossoverChild(parent1Chromosome, parent2Chromosome, complementaryChoices)
+
+		return child1, child2
+	}
+
+	return *parent1, *parent2
 }
@@ -0,0 +1,178 @@
+package ga
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func isPermutation(chromosome IntVectorChromosome) bool {
+	seen := make([]bool, len(chromosome))
+	for _, gene := range chromosome {
+		if gene < 0 || gene >= len(chromosome) || seen[gene] {
+			return false
+		}
+		seen[gene] = true
+	}
+	return true
+}
+
+func TestPermutationCrossoverOperators(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for name, crossover := range PermutationCrossoverOperators {
+		for i := 0; i < 100; i++ {
+			parent1 := &Individual{Chromosome: PermutationInitialization(random, 20)}
+			parent2 := &Individual{Chromosome: PermutationInitialization(random, 20)}
+
+			child1, child2 := crossover(random, parent1, parent2, 1.0)
+			if !isPermutation(child1.Chromosome.(IntVectorChromosome)) || !isPermutation(child2.Chromosome.(IntVectorChromosome)) {
+				t.Fatalf("%v: invalid children %v and %v", name, child1.Chromosome, child2.Chromosome)
+			}
+		}
+	}
+}
+
+func TestPermutationMutationOperators(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for name, mutation := range PermutationMutationOperators {
+		for i := 0; i < 100; i++ {
+			individual := &Individual{Chromosome: PermutationInitialization(random, 20)}
+
+			mutation(random, individual, 0.5)
+			if !isPermutation(individual.Chromosome.(IntVectorChromosome)) {
+				t.Fatalf("%v: invalid chromosome %v", name, individual.Chromosome)
+			}
+		}
+	}
+}
+
+func TestMutationOperatorsRatePerGene(t *testing.T) {
+	operators := make(map[string]func(random *rand.Rand, individual *Individual, mutationRate float64))
+	for name, mutation := range PermutationMutationOperators {
+		operators[name] = mutation
+	}
+	for name, mutation := range JobShopMutationOperators {
+		operators[name] = mutation
+	}
+
+	random := rand.New(rand.NewSource(1))
+	for name, mutation := range operators {
+		// With 100 genes and a rate of 0.01, about 63% of the chromosomes change.
+		changed := 0
+		for i := 0; i < 100; i++ {
+			chromosome := PermutationInitialization(random, 100)
+			individual := &Individual{Chromosome: append(IntVectorChromosome{}, chromosome...)}
+
+			mutation(random, individual, 0.01)
+			if !reflect.DeepEqual(individual.Chromosome, chromosome) {
+				changed++
+			}
+		}
+		if changed < 40 {
+			t.Errorf("%v: expected most chromosomes to change, got %v out of 100", name, changed)
+		}
+	}
+}
+
+func TestCycleCrossover(t *testing.T) {
+	parent1 := &Individual{Chromosome: IntVectorChromosome{0, 1, 2, 3, 4, 5, 6, 7}}
+	parent2 := &Individual{Chromosome: IntVectorChromosome{1, 2, 0, 4, 3, 6, 7, 5}}
+
+	child1, child2 := CycleCrossover(rand.New(rand.NewSource(1)), parent1, parent2, 1.0)
+
+	expected1 := IntVectorChromosome{0, 1, 2, 4, 3, 5, 6, 7}
+	expected2 := IntVectorChromosome{1, 2, 0, 3, 4, 6, 7, 5}
+	for i := range expected1 {
+		if child1.Chromosome.(IntVectorChromosome)[i] != expected1[i] || child2.Chromosome.(IntVectorChromosome)[i] != expected2[i] {
+			t.Fatalf("expected %v and %v, got %v and %v", expected1, expected2, child1.Chromosome, child2.Chromosome)
+		}
+	}
+}
+
+func TestEuclideanDistanceFitnessFunction(t *testing.T) {
+	nodes := [][2]int{{0, 0}, {0, 3}, {4, 3}, {4, 0}}
+
+	distance := EuclideanDistanceFitnessFunction(IntVectorChromosome{0, 1, 2, 3}, nodes)
+	if distance != 14 {
+		t.Errorf("expected 14, got %v", distance)
+	}
+
+	distance = EuclideanDistanceFitnessFunction(IntVectorChromosome{0, 2, 1, 3}, nodes)
+	if distance != 18 {
+		t.Errorf("expected 18, got %v", distance)
+	}
+}
+
+func sameMultiset(chromosome1, chromosome2 IntVectorChromosome) bool {
+	if len(chromosome1) != len(chromosome2) {
+		return false
+	}
+	counts := make(map[int]int)
+	for i := range chromosome1 {
+		counts[chromosome1[i]]++
+		counts[chromosome2[i]]--
+	}
+	for _, count := range counts {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestJobShopCrossoverOperators(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	repetitions := []int{4, 4, 4, 4, 4}
+	for name, crossover := range JobShopCrossoverOperators {
+		for i := 0; i < 100; i++ {
+			parent1 := &Individual{Chromosome: PermutationWithRepetitionInitialization(random, repetitions)}
+			parent2 := &Individual{Chromosome: PermutationWithRepetitionInitialization(random, repetitions)}
+
+			child1, child2 := crossover(random, parent1, parent2, 1.0)
+			parentChromosome := parent1.Chromosome.(IntVectorChromosome)
+			if !sameMultiset(child1.Chromosome.(IntVectorChromosome), parentChromosome) || !sameMultiset(child2.Chromosome.(IntVectorChromosome), parentChromosome) {
+				t.Fatalf("%v: invalid children %v and %v", name, child1.Chromosome, child2.Chromosome)
+			}
+		}
+	}
+}
+
+func TestJobShopMutationOperators(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	repetitions := []int{4, 4, 4, 4, 4}
+	for name, mutation := range JobShopMutationOperators {
+		for i := 0; i < 100; i++ {
+			chromosome := PermutationWithRepetitionInitialization(random, repetitions)
+			individual := &Individual{Chromosome: append(IntVectorChromosome{}, chromosome...)}
+
+			mutation(random, individual, 0.5)
+			if !sameMultiset(individual.Chromosome.(IntVectorChromosome), chromosome) {
+				t.Fatalf("%v: invalid chromosome %v", name, individual.Chromosome)
+			}
+		}
+	}
+}
+
+func TestBitOperatorsMatchByteOperators(t *testing.T) {
+	size := 130
+	random := rand.New(rand.NewSource(1))
+	parent1 := ByteVectorChromosomeInitialization(random, size, 0, 1)
+	parent2 := ByteVectorChromosomeInitialization(random, size, 0, 1)
+
+	for seed := int64(0); seed < 20; seed++ {
+		byteRandom := rand.New(rand.NewSource(seed))
+		child1, child2 := TwoPointsCrossover(byteRandom, &Individual{Chromosome: parent1}, &Individual{Chromosome: parent2}, 1.0)
+		ByteRandomMutation(byteRandom, &child1, 0, 1, 0.1)
+
+		bitRandom := rand.New(rand.NewSource(seed))
+		bitChild1, bitChild2 := BitTwoPointsCrossover(bitRandom, &Individual{Chromosome: NewBitVectorChromosome(parent1)}, &Individual{Chromosome: NewBitVectorChromosome(parent2)}, size, 1.0)
+		BitRandomMutation(bitRandom, &bitChild1, size, 0.1)
+
+		if actual := bitChild1.Chromosome.(BitVectorChromosome).Bytes(size); !reflect.DeepEqual(actual, child1.Chromosome) {
+			t.Errorf("seed %v: expected %v, got %v", seed, child1.Chromosome, actual)
+		}
+		if actual := bitChild2.Chromosome.(BitVectorChromosome).Bytes(size); !reflect.DeepEqual(actual, child2.Chromosome) {
+			t.Errorf("seed %v: expected %v, got %v", seed, child2.Chromosome, actual)
+		}
+	}
+}
@@ -4,10 +4,9 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"reflect"
 
 	"github.com/golang/snappy"
-
-	"github.com/pasqualesalza/amqpga/util"
 )
 
 type Individual struct {
@@ -27,22 +26,90 @@ func (individual *Individual) String() string {
 	return fmt.Sprintf("{Generation: %v, Id: %v, Chromosome: %v, FitnessValue: %v}", individual.Generation, individual.Id, individual.Chromosome, individual.FitnessValue)
 }
 
-func (individual *Individual) Encode() []byte {
+// Copies a slice chromosome, so that it can be changed without affecting the original.
+func CloneChromosome(chromosome Chromosome) Chromosome {
+	value := reflect.ValueOf(chromosome)
+	if value.Kind() != reflect.Slice {
+		return chromosome
+	}
+	clone := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+	reflect.Copy(clone, value)
+	return clone.Interface()
+}
+
+// Checks that an individual received from another node has the chromosome type
+// and size of a reference individual of the problem, and the type of its
+// fitness value if evaluated, so that the operators can handle it.
+func (individual *Individual) Fits(reference *Individual) error {
+	if reflect.TypeOf(individual.Chromosome) != reflect.TypeOf(reference.Chromosome) {
+		return fmt.Errorf("individual %v has a chromosome of type %T, expected %T", individual.Id, individual.Chromosome, reference.Chromosome)
+	}
+	value := reflect.ValueOf(individual.Chromosome)
+	expected := reflect.ValueOf(reference.Chromosome)
+	if value.Kind() == reflect.Slice && value.Len() != expected.Len() {
+		return fmt.Errorf("individual %v has a chromosome of size %v, expected %v", individual.Id, value.Len(), expected.Len())
+	}
+	if individual.FitnessValue != nil && reference.FitnessValue != nil && reflect.TypeOf(individual.FitnessValue) != reflect.TypeOf(reference.FitnessValue) {
+		return fmt.Errorf("individual %v has a fitness value of type %T, expected %T", individual.Id, individual.FitnessValue, reference.FitnessValue)
+	}
+	return nil
+}
+
+func (individual *Individual) Encode() ([]byte, error) {
 	var buffer bytes.Buffer
 	encoder := gob.NewEncoder(&buffer)
-	err := encoder.Encode(*individual)
-	util.FailOnError(err, "Failed to encode individual")
-	bytes := buffer.Bytes()
-	bytes = snappy.Encode(nil, bytes)
-	return bytes
-}
-
-func (individual *Individual) Decode(data []byte) {
-	data, _ = snappy.Decode(nil, data)
-	buffer := *bytes.NewBuffer(data)
-	decoder := gob.NewDecoder(&buffer)
-	err := decoder.Decode(individual)
-	util.FailOnError(err, "Failed to decode individual")
+	if err := encoder.Encode(*individual); err != nil {
+		return nil, fmt.Errorf("failed to encode individual: %v", err)
+	}
+	return snappy.Encode(nil, buffer.Bytes()), nil
+}
+
+func (individual *Individual) Decode(data []byte) error {
+	data, err := snappy.Decode(nil, data)
+	if err != nil {
+		return fmt.Errorf("failed to decompress individual: %v", err)
+	}
+	buffer := bytes.NewBuffer(data)
+	decoder := gob.NewDecoder(buffer)
+	if err := decoder.Decode(individual); err != nil {
+		return fmt.Errorf("failed to decode individual: %v", err)
+	}
+	return nil
+}
+
+// Encodes a batch of individuals in a single message.
+func EncodeIndividuals(individuals []*Individual) ([]byte, error) {
+	batch := make([]Individual, len(individuals))
+	for i, individual := range individuals {
+		batch[i] = *individual
+	}
+
+	var buffer bytes.Buffer
+	encoder := gob.NewEncoder(&buffer)
+	if err := encoder.Encode(batch); err != nil {
+		return nil, fmt.Errorf("failed to encode individuals: %v", err)
+	}
+	return snappy.Encode(nil, buffer.Bytes()), nil
+}
+
+// Decodes a batch of individuals encoded by EncodeIndividuals.
+func DecodeIndividuals(data []byte) ([]*Individual, error) {
+	data, err := snappy.Decode(nil, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress individuals: %v", err)
+	}
+	buffer := bytes.NewBuffer(data)
+	decoder := gob.NewDecoder(buffer)
+	var batch []Individual
+	if err := decoder.Decode(&batch); err != nil {
+		return nil, fmt.Errorf("failed to decode individuals: %v", err)
+	}
+
+	individuals := make([]*Individual, len(batch))
+	for i := range batch {
+		individuals[i] = &batch[i]
+	}
+	return individuals, nil
 }
 
 type SortByIdIndividuals []*Individual
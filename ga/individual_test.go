@@ -0,0 +1,64 @@
+package ga
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEncodeIndividuals(t *testing.T) {
+	individuals := []*Individual{
+		{Id: 3, Generation: 1, Chromosome: Float64VectorChromosome{1.5, -2}, FitnessValue: Float64FitnessValue(6.25)},
+		{Id: 4, Generation: 1, Chromosome: Float64VectorChromosome{0, 1}},
+	}
+
+	data, err := EncodeIndividuals(individuals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeIndividuals(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, individuals) {
+		t.Errorf("expected %v, got %v", individuals, decoded)
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	inputs := [][]byte{
+		nil,
+		[]byte("not snappy"),
+		{0x05, 0x10, 0x01, 0x02, 0x03, 0x04, 0x05},
+	}
+
+	for _, input := range inputs {
+		if err := new(Individual).Decode(input); err == nil {
+			t.Errorf("expected an error decoding %v", input)
+		}
+		if _, err := DecodeIndividuals(input); err == nil {
+			t.Errorf("expected an error decoding the batch %v", input)
+		}
+	}
+}
+
+func TestIndividualFits(t *testing.T) {
+	reference := &Individual{Chromosome: IntVectorChromosome{0, 1, 2}, FitnessValue: IntFitnessValue(3)}
+
+	if err := (&Individual{Chromosome: IntVectorChromosome{2, 1, 0}, FitnessValue: IntFitnessValue(4)}).Fits(reference); err != nil {
+		t.Error(err)
+	}
+	if err := (&Individual{Chromosome: IntVectorChromosome{2, 1, 0}}).Fits(reference); err != nil {
+		t.Error(err)
+	}
+
+	misfits := []*Individual{
+		{Chromosome: Float64VectorChromosome{2, 1, 0}},
+		{Chromosome: IntVectorChromosome{1, 0}},
+		{Chromosome: IntVectorChromosome{2, 1, 0}, FitnessValue: Float64FitnessValue(4)},
+	}
+	for _, individual := range misfits {
+		if err := individual.Fits(reference); err == nil {
+			t.Errorf("expected an error for %v", individual)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package ga
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Default settings of the island model.
+const (
+	DefaultTopology           = "ring"
+	DefaultMigrantSelection   = "best"
+	DefaultMigrantReplacement = "worst"
+)
+
+// Topologies of the island model: each function returns the islands receiving
+// the migrants of an island.
+var Topologies = map[string]func(island, islandsNumber int) []int{
+	"ring":  RingNeighbours,
+	"torus": TorusNeighbours,
+	"full":  FullNeighbours,
+}
+
+// Policies choosing the migrants of a population.
+var MigrantSelectionPolicies = map[string]func(random *rand.Rand, population []*Individual, migrantsNumber int, minimization bool) []*Individual{
+	"best":   BestMigrantSelection,
+	"random": RandomMigrantSelection,
+}
+
+// Policies choosing the individuals replaced by the immigrants.
+var MigrantReplacementPolicies = map[string]func(random *rand.Rand, population []*Individual, immigrants []*Individual, minimization bool){
+	"worst":  WorstMigrantReplacement,
+	"random": RandomMigrantReplacement,
+}
+
+// Exchanges individuals with the other islands between two generations.
+type Migrator interface {
+	Migrate(population []*Individual, generation int64) ([]*Individual, error)
+}
+
+// Returns the neighbours of an island in a topology.
+func Neighbours(topology string, island, islandsNumber int) ([]int, error) {
+	neighbours, ok := Topologies[topology]
+	if !ok {
+		return nil, fmt.Errorf("unknown topology %v", topology)
+	}
+	if island < 0 || island >= islandsNumber {
+		return nil, fmt.Errorf("island %v out of %v islands", island, islandsNumber)
+	}
+	return neighbours(island, islandsNumber), nil
+}
+
+// Sends the migrants to the next island of a unidirectional ring.
+func RingNeighbours(island, islandsNumber int) []int {
+	if islandsNumber < 2 {
+		return nil
+	}
+	return []int{(island + 1) % islandsNumber}
+}
+
+// Sends the migrants to the four adjacent islands of a two-dimensional torus,
+// as square as the number of islands allows.
+func TorusNeighbours(island, islandsNumber int) []int {
+	rows := int(math.Sqrt(float64(islandsNumber)))
+	for islandsNumber%rows != 0 {
+		rows--
+	}
+	columns := islandsNumber / rows
+
+	row, column := island/columns, island%columns
+	candidates := []int{
+		((row+rows-1)%rows)*columns + column,
+		((row+1)%rows)*columns + column,
+		row*columns + (column+columns-1)%columns,
+		row*columns + (column+1)%columns,
+	}
+
+	// Removes the duplicates of the small tori.
+	neighbours := make([]int, 0, len(candidates))
+	for _, candidate := range candidates {
+		duplicate := candidate == island
+		for _, neighbour := range neighbours {
+			duplicate = duplicate || neighbour == candidate
+		}
+		if !duplicate {
+			neighbours = append(neighbours, candidate)
+		}
+	}
+	return neighbours
+}
+
+// Sends the migrants to every other island.
+func FullNeighbours(island, islandsNumber int) []int {
+	neighbours := make([]int, 0, islandsNumber)
+	for j := 0; j < islandsNumber; j++ {
+		if j != island {
+			neighbours = append(neighbours, j)
+		}
+	}
+	return neighbours
+}
+
+// Sorts a copy of the population from the best to the worst individual.
+func sortByBest(population []*Individual, minimization bool) []*Individual {
+	if minimization {
+		sortedPopulation := make(SortByMinFitnessValueIndividuals, len(population))
+		copy(sortedPopulation, population)
+		sort.Stable(sortedPopulation)
+		return sortedPopulation
+	}
+	sortedPopulation := make(SortByMaxFitnessValueIndividuals, len(population))
+	copy(sortedPopulation, population)
+	sort.Stable(sortedPopulation)
+	return sortedPopulation
+}
+
+// Selects the best individuals as migrants.
+func BestMigrantSelection(random *rand.Rand, population []*Individual, migrantsNumber int, minimization bool) []*Individual {
+	if migrantsNumber > len(population) {
+		migrantsNumber = len(population)
+	}
+	return sortByBest(population, minimization)[:migrantsNumber]
+}
+
+// Selects random distinct individuals as migrants.
+func RandomMigrantSelection(random *rand.Rand, population []*Individual, migrantsNumber int, minimization bool) []*Individual {
+	if migrantsNumber > len(population) {
+		migrantsNumber = len(population)
+	}
+	migrants := make([]*Individual, migrantsNumber)
+	for i, j := range random.Perm(len(population))[:migrantsNumber] {
+		migrants[i] = population[j]
+	}
+	return migrants
+}
+
+// Replaces the worst individuals with the immigrants.
+func WorstMigrantReplacement(random *rand.Rand, population []*Individual, immigrants []*Individual, minimization bool) {
+	sortedPopulation := sortByBest(population, minimization)
+	positions := make(map[*Individual]int, len(population))
+	for j, individual := range population {
+		positions[individual] = j
+	}
+
+	for i := 0; i < len(immigrants) && i < len(population); i++ {
+		worst := sortedPopulation[len(sortedPopulation)-1-i]
+		population[positions[worst]] = immigrants[i]
+	}
+}
+
+// Replaces random distinct individuals with the immigrants.
+func RandomMigrantReplacement(random *rand.Rand, population []*Individual, immigrants []*Individual, minimization bool) {
+	for i, j := range random.Perm(len(population)) {
+		if i >= len(immigrants) {
+			break
+		}
+		population[j] = immigrants[i]
+	}
+}
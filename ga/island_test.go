@@ -0,0 +1,154 @@
+package ga
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestNeighbours(t *testing.T) {
+	tests := []struct {
+		topology      string
+		island        int
+		islandsNumber int
+		expected      []int
+	}{
+		{"ring", 0, 4, []int{1}},
+		{"ring", 3, 4, []int{0}},
+		{"ring", 0, 1, nil},
+		{"full", 1, 4, []int{0, 2, 3}},
+		{"torus", 0, 9, []int{1, 2, 3, 6}},
+		{"torus", 4, 9, []int{1, 3, 5, 7}},
+		{"torus", 0, 6, []int{1, 2, 3}},
+		{"torus", 0, 2, []int{1}},
+	}
+
+	for _, test := range tests {
+		neighbours, err := Neighbours(test.topology, test.island, test.islandsNumber)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Ints(neighbours)
+		if len(neighbours) != len(test.expected) || (len(neighbours) > 0 && !reflect.DeepEqual(neighbours, test.expected)) {
+			t.Errorf("%v %v/%v: expected %v, got %v", test.topology, test.island, test.islandsNumber, test.expected, neighbours)
+		}
+	}
+
+	if _, err := Neighbours("star", 0, 4); err == nil {
+		t.Error("expected an error for an unknown topology")
+	}
+	if _, err := Neighbours("ring", 4, 4); err == nil {
+		t.Error("expected an error for an island out of range")
+	}
+}
+
+func newFitnessPopulation(fitnessValues ...float64) []*Individual {
+	population := make([]*Individual, len(fitnessValues))
+	for j, fitnessValue := range fitnessValues {
+		population[j] = &Individual{Id: int64(j), FitnessValue: Float64FitnessValue(fitnessValue)}
+	}
+	return population
+}
+
+func TestBestMigrantSelection(t *testing.T) {
+	population := newFitnessPopulation(3, 1, 4, 2)
+
+	migrants := BestMigrantSelection(nil, population, 2, true)
+	if migrants[0].Id != 1 || migrants[1].Id != 3 {
+		t.Errorf("expected individuals 1 and 3, got %v", migrants)
+	}
+
+	migrants = BestMigrantSelection(nil, population, 2, false)
+	if migrants[0].Id != 2 || migrants[1].Id != 0 {
+		t.Errorf("expected individuals 2 and 0, got %v", migrants)
+	}
+}
+
+func TestWorstMigrantReplacement(t *testing.T) {
+	population := newFitnessPopulation(3, 1, 4, 2)
+	immigrants := newFitnessPopulation(0, 0)
+
+	WorstMigrantReplacement(nil, population, immigrants, true)
+	for _, j := range []int{0, 2} {
+		if population[j] != immigrants[0] && population[j] != immigrants[1] {
+			t.Errorf("individual %v not replaced", j)
+		}
+	}
+	if population[1].Id != 1 || population[3].Id != 3 {
+		t.Errorf("best individuals replaced: %v", population)
+	}
+}
+
+func TestRandomMigrantPolicies(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	population := newFitnessPopulation(3, 1, 4, 2)
+
+	migrants := RandomMigrantSelection(random, population, 10, true)
+	if len(migrants) != len(population) {
+		t.Errorf("expected %v migrants, got %v", len(population), len(migrants))
+	}
+
+	immigrants := newFitnessPopulation(0, 0)
+	RandomMigrantReplacement(random, population, immigrants, true)
+	replaced := 0
+	for _, individual := range population {
+		if individual == immigrants[0] || individual == immigrants[1] {
+			replaced++
+		}
+	}
+	if replaced != len(immigrants) {
+		t.Errorf("expected %v replaced individuals, got %v", len(immigrants), replaced)
+	}
+}
+
+// Exchanges migrants between engines running in the same process.
+type channelMigrator struct {
+	interval   int64
+	outgoing   chan *Individual
+	incoming   chan *Individual
+	migrations int
+}
+
+func (migrator *channelMigrator) Migrate(population []*Individual, generation int64) ([]*Individual, error) {
+	if (generation+1)%migrator.interval != 0 {
+		return population, nil
+	}
+	migrator.migrations++
+
+	for _, migrant := range BestMigrantSelection(nil, population, 1, true) {
+		copied := *migrant
+		migrator.outgoing <- &copied
+	}
+
+	immigrants := make([]*Individual, 0, 1)
+	select {
+	case immigrant := <-migrator.incoming:
+		immigrants = append(immigrants, immigrant)
+	default:
+	}
+	WorstMigrantReplacement(nil, population, immigrants, true)
+
+	return population, nil
+}
+
+func TestEngineMigration(t *testing.T) {
+	problem, _ := NewProblem("sphere", ProblemParameters{ChromosomeSize: 10})
+	channel := make(chan *Individual, 100)
+	migrator := &channelMigrator{interval: 5, outgoing: channel, incoming: channel}
+
+	engine := newTestEngine(problem, &SequentialEvaluator{FitnessFunction: problem.Evaluate})
+	engine.Migrator = migrator
+
+	population, err := engine.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := int(engine.GenerationsNumber / migrator.interval); migrator.migrations != expected {
+		t.Errorf("expected %v migrations, got %v", expected, migrator.migrations)
+	}
+	if len(population) != engine.PopulationSize {
+		t.Errorf("expected %v individuals, got %v", engine.PopulationSize, len(population))
+	}
+}
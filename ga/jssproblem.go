@@ -0,0 +1,151 @@
+package ga
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/pasqualesalza/amqpga/ga/data/jss"
+)
+
+func init() {
+	RegisterProblem("jss", newJSSProblem)
+	RegisterContextProblem("jss", newJSSProblemFromContext)
+}
+
+// Crossover operators for permutations with repetition, by name.
+var JobShopCrossoverOperators = map[string]func(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual){
+	"jox": JobOrderCrossover,
+	"ppx": PrecedencePreservativeCrossover,
+}
+
+// Mutation operators for permutations with repetition, by name.
+var JobShopMutationOperators = map[string]func(random *rand.Rand, individual *Individual, mutationRate float64){
+	"swap":      SwapMutation,
+	"insertion": InsertionMutation,
+}
+
+// Schedule builders decoding a chromosome into its makespan, by name.
+var ScheduleBuilders = map[string]func(schedule IntVectorChromosome, instance [][][2]int) IntFitnessValue{
+	"semi-active": MaxMakespanFitnessFunction,
+	"active":      ActiveMakespanFitnessFunction,
+}
+
+const (
+	DefaultJSSInstance      = "abz5"
+	DefaultJobShopCrossover = "jox"
+	DefaultJobShopMutation  = "swap"
+	DefaultScheduleBuilder  = "active"
+)
+
+// Minimization of the makespan of a job-shop scheduling problem, with
+// operation-based chromosomes: permutations with repetition of the job indices.
+type JSSProblem struct {
+	Instance          [][][2]int
+	ScheduleBuilder   func(schedule IntVectorChromosome, instance [][][2]int) IntFitnessValue
+	CrossoverOperator func(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual)
+	MutationOperator  func(random *rand.Rand, individual *Individual, mutationRate float64)
+}
+
+func newJSSProblem(parameters ProblemParameters) (Problem, error) {
+	instance, err := jssInstance(parameters)
+	if err != nil {
+		return nil, err
+	}
+	return newJSSProblemFromContext(parameters, instance)
+}
+
+// Builds the problem of an instance already loaded.
+func newJSSProblemFromContext(parameters ProblemParameters, data interface{}) (Problem, error) {
+	instance, ok := data.([][][2]int)
+	if !ok {
+		return nil, fmt.Errorf("invalid JSS context %T", data)
+	}
+
+	scheduleBuilderName := parameters.ScheduleBuilder
+	if scheduleBuilderName == "" {
+		scheduleBuilderName = DefaultScheduleBuilder
+	}
+	scheduleBuilder, ok := ScheduleBuilders[scheduleBuilderName]
+	if !ok {
+		return nil, fmt.Errorf("unknown schedule builder %v", scheduleBuilderName)
+	}
+
+	crossoverName := parameters.CrossoverOperator
+	if crossoverName == "" {
+		crossoverName = DefaultJobShopCrossover
+	}
+	crossoverOperator, ok := JobShopCrossoverOperators[crossoverName]
+	if !ok {
+		return nil, fmt.Errorf("unknown job-shop crossover operator %v", crossoverName)
+	}
+
+	mutationName := parameters.MutationOperator
+	if mutationName == "" {
+		mutationName = DefaultJobShopMutation
+	}
+	mutationOperator, ok := JobShopMutationOperators[mutationName]
+	if !ok {
+		return nil, fmt.Errorf("unknown job-shop mutation operator %v", mutationName)
+	}
+
+	return &JSSProblem{
+		Instance:          instance,
+		ScheduleBuilder:   scheduleBuilder,
+		CrossoverOperator: crossoverOperator,
+		MutationOperator:  mutationOperator,
+	}, nil
+}
+
+// Loads the instance file or looks up the compiled-in instance selected by the parameters.
+func jssInstance(parameters ProblemParameters) ([][][2]int, error) {
+	if parameters.InstanceFile != "" {
+		instance, err := jss.Load(parameters.InstanceFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the JSS instance %v: %v", parameters.InstanceFile, err)
+		}
+		return instance, nil
+	}
+
+	instanceName := parameters.InstanceName
+	if instanceName == "" {
+		instanceName = DefaultJSSInstance
+	}
+	instance, ok := jss.Instances[instanceName]
+	if !ok {
+		return nil, fmt.Errorf("unknown JSS instance %v", instanceName)
+	}
+	return instance, nil
+}
+
+// Returns the instance.
+func (problem *JSSProblem) Context() interface{} {
+	return problem.Instance
+}
+
+func (problem *JSSProblem) NewChromosome(random *rand.Rand) Chromosome {
+	repetitions := make([]int, len(problem.Instance))
+	for job, operations := range problem.Instance {
+		repetitions[job] = len(operations)
+	}
+	return PermutationWithRepetitionInitialization(random, repetitions)
+}
+
+func (problem *JSSProblem) Bounds() (min, max interface{}) {
+	return 0, len(problem.Instance) - 1
+}
+
+func (problem *JSSProblem) Evaluate(individual *Individual) FitnessValue {
+	return problem.ScheduleBuilder(individual.Chromosome.(IntVectorChromosome), problem.Instance)
+}
+
+func (problem *JSSProblem) Minimization() bool {
+	return true
+}
+
+func (problem *JSSProblem) Crossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	return problem.CrossoverOperator(random, parent1, parent2, crossoverRate)
+}
+
+func (problem *JSSProblem) Mutation(random *rand.Rand, individual *Individual, mutationRate float64) {
+	problem.MutationOperator(random, individual, mutationRate)
+}
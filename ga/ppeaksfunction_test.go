@@ -1,20 +1,22 @@
 package ga
 
 import (
+	"math/rand"
 	"testing"
 )
 
 // P-Peaks function utility.
 func benchmarkPPeaksFitnessFunction(peaksNumber int, chromosomeSize int, b *testing.B) {
+	random := rand.New(rand.NewSource(1))
 	peaks := make([]ByteVectorChromosome, peaksNumber)
 	for i := 0; i < peaksNumber; i++ {
-		peaks[i] = ByteVectorChromosomeInitialization(chromosomeSize, 0, 1)
+		peaks[i] = ByteVectorChromosomeInitialization(random, chromosomeSize, 0, 1)
 	}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
-		chromosome := ByteVectorChromosomeInitialization(chromosomeSize, 0, 1)
+		chromosome := ByteVectorChromosomeInitialization(random, chromosomeSize, 0, 1)
 		b.StartTimer()
 		PPeaksFitnessFunction(chromosome, peaks)
 	}
@@ -203,3 +205,63 @@ func BenchmarkPPeaksFunctionFitnessEvaluation_P8192_C2048(b *testing.B) {
 func BenchmarkPPeaksFunctionFitnessEvaluation_P8192_C4096(b *testing.B) {
 	benchmarkPPeaksFitnessFunction(8192, 4096, b)
 }
+
+func TestBitPPeaksFitnessFunction(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for _, size := range []int{1, 63, 64, 65, 200} {
+		peaks := make([]ByteVectorChromosome, 8)
+		bitPeaks := make([]BitVectorChromosome, len(peaks))
+		for i := range peaks {
+			peaks[i] = ByteVectorChromosomeInitialization(random, size, 0, 1)
+			bitPeaks[i] = NewBitVectorChromosome(peaks[i])
+		}
+
+		for i := 0; i < 10; i++ {
+			chromosome := ByteVectorChromosomeInitialization(random, size, 0, 1)
+			expected := PPeaksFitnessFunction(chromosome, peaks)
+			if actual := BitPPeaksFitnessFunction(NewBitVectorChromosome(chromosome), bitPeaks, size); actual != expected {
+				t.Errorf("size %v: expected %v, got %v", size, expected, actual)
+			}
+		}
+	}
+}
+
+// Bit-packed P-Peaks function utility.
+func benchmarkBitPPeaksFitnessFunction(peaksNumber int, chromosomeSize int, b *testing.B) {
+	random := rand.New(rand.NewSource(1))
+	peaks := make([]BitVectorChromosome, peaksNumber)
+	for i := 0; i < peaksNumber; i++ {
+		peaks[i] = BitVectorChromosomeInitialization(random, chromosomeSize)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		chromosome := BitVectorChromosomeInitialization(random, chromosomeSize)
+		b.StartTimer()
+		BitPPeaksFitnessFunction(chromosome, peaks, chromosomeSize)
+	}
+}
+
+// Bit-packed P-Peaks P512.
+func BenchmarkBitPPeaksFunctionFitnessEvaluation_P512_C64(b *testing.B) {
+	benchmarkBitPPeaksFitnessFunction(512, 64, b)
+}
+func BenchmarkBitPPeaksFunctionFitnessEvaluation_P512_C128(b *testing.B) {
+	benchmarkBitPPeaksFitnessFunction(512, 128, b)
+}
+func BenchmarkBitPPeaksFunctionFitnessEvaluation_P512_C256(b *testing.B) {
+	benchmarkBitPPeaksFitnessFunction(512, 256, b)
+}
+func BenchmarkBitPPeaksFunctionFitnessEvaluation_P512_C512(b *testing.B) {
+	benchmarkBitPPeaksFitnessFunction(512, 512, b)
+}
+func BenchmarkBitPPeaksFunctionFitnessEvaluation_P512_C1024(b *testing.B) {
+	benchmarkBitPPeaksFitnessFunction(512, 1024, b)
+}
+func BenchmarkBitPPeaksFunctionFitnessEvaluation_P512_C2048(b *testing.B) {
+	benchmarkBitPPeaksFitnessFunction(512, 2048, b)
+}
+func BenchmarkBitPPeaksFunctionFitnessEvaluation_P512_C4096(b *testing.B) {
+	benchmarkBitPPeaksFitnessFunction(512, 4096, b)
+}
@@ -0,0 +1,121 @@
+package ga
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// A problem to be solved by the genetic algorithm.
+type Problem interface {
+	// Creates a random chromosome.
+	NewChromosome(random *rand.Rand) Chromosome
+
+	// Returns the bounds of the genes.
+	Bounds() (min, max interface{})
+
+	// Computes the fitness value of an individual.
+	Evaluate(individual *Individual) FitnessValue
+
+	// Tells if the fitness value has to be minimized or maximized.
+	Minimization() bool
+
+	// The default crossover operator of the problem.
+	Crossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual)
+
+	// The default mutation operator of the problem.
+	Mutation(random *rand.Rand, individual *Individual, mutationRate float64)
+}
+
+// The parameters used to build a problem.
+type ProblemParameters struct {
+	ChromosomeSize    int
+	PeaksNumber       int64
+	BlockSize         int
+	Epistasis         int
+	SleepTime         int64
+	RandomSeed        int64
+	InstanceName      string
+	InstanceFile      string
+	ShiftFile         string
+	RotationFile      string
+	CrossoverOperator string
+	MutationOperator  string
+	ScheduleBuilder   string
+}
+
+// Builds a problem from its parameters.
+type ProblemFactory func(parameters ProblemParameters) (Problem, error)
+
+var problemFactories = make(map[string]ProblemFactory)
+
+// Registers a problem with a name. It panics if the name is already registered.
+func RegisterProblem(name string, factory ProblemFactory) {
+	if _, ok := problemFactories[name]; ok {
+		panic(fmt.Sprintf("problem %v already registered", name))
+	}
+	problemFactories[name] = factory
+}
+
+// Builds the problem registered with the name.
+func NewProblem(name string, parameters ProblemParameters) (Problem, error) {
+	factory, ok := problemFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown problem %v", name)
+	}
+	return factory(parameters)
+}
+
+// Describes a problem, so that another node can build it again.
+type ProblemDescriptor struct {
+	Name       string
+	Parameters ProblemParameters
+}
+
+func (descriptor *ProblemDescriptor) Encode() ([]byte, error) {
+	data, err := json.Marshal(descriptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode problem descriptor: %v", err)
+	}
+	return data, nil
+}
+
+func (descriptor *ProblemDescriptor) Decode(data []byte) error {
+	if err := json.Unmarshal(data, descriptor); err != nil {
+		return fmt.Errorf("failed to decode problem descriptor: %v", err)
+	}
+	return nil
+}
+
+// Builds the described problem.
+func (descriptor *ProblemDescriptor) NewProblem() (Problem, error) {
+	return NewProblem(descriptor.Name, descriptor.Parameters)
+}
+
+// Returns the sorted names of the registered problems.
+func ProblemNames() []string {
+	names := make([]string, 0, len(problemFactories))
+	for name := range problemFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Builds the engine operators of a problem, with tournament selection and its
+// default crossover and mutation.
+func ProblemOperators(problem Problem, tournamentSelectionSize int, crossoverRate float64, mutationRate float64) Operators {
+	return Operators{
+		Initialization: problem.NewChromosome,
+		Selection: func(random *rand.Rand, individuals []*Individual) *Individual {
+			return TournamentSelection(random, individuals, tournamentSelectionSize, problem.Minimization())
+		},
+		Crossover: func(random *rand.Rand, parent1, parent2 *Individual) (Individual, Individual) {
+			return problem.Crossover(random, parent1, parent2, crossoverRate)
+		},
+		Mutation: func(random *rand.Rand, individual *Individual) {
+			problem.Mutation(random, individual, mutationRate)
+		},
+	}
+}
@@ -0,0 +1,115 @@
+package ga
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testProblemParameters = ProblemParameters{
+	ChromosomeSize: 24,
+	PeaksNumber:    8,
+	SleepTime:      0,
+	RandomSeed:     42,
+}
+
+func TestNewProblemUnknown(t *testing.T) {
+	if _, err := NewProblem("unknown", testProblemParameters); err == nil {
+		t.Error("expected an error for an unknown problem")
+	}
+}
+
+func TestRegisteredProblems(t *testing.T) {
+	for _, name := range ProblemNames() {
+		problem, err := NewProblem(name, testProblemParameters)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		engine := &Engine{
+			PopulationSize:    10,
+			GenerationsNumber: 3,
+			Minimization:      problem.Minimization(),
+			Operators:         ProblemOperators(problem, 2, 1.0, 0.1),
+			Evaluator:         &SequentialEvaluator{FitnessFunction: problem.Evaluate},
+		}
+
+		population, err := engine.Run()
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if len(population) != engine.PopulationSize {
+			t.Errorf("%v: expected %v individuals, got %v", name, engine.PopulationSize, len(population))
+		}
+		for _, individual := range population {
+			if individual.FitnessValue == nil {
+				t.Errorf("%v: individual %v not evaluated", name, individual.Id)
+			}
+			if individual.Generation != engine.GenerationsNumber {
+				t.Errorf("%v: expected generation %v, got %v", name, engine.GenerationsNumber, individual.Generation)
+			}
+		}
+	}
+}
+
+func TestProblemDescriptorRoundTrip(t *testing.T) {
+	descriptor := &ProblemDescriptor{Name: "sphere", Parameters: testProblemParameters}
+	data, err := descriptor.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := new(ProblemDescriptor)
+	if err := decoded.Decode(data); err != nil {
+		t.Fatal(err)
+	}
+	if *decoded != *descriptor {
+		t.Errorf("expected %v, got %v", descriptor, decoded)
+	}
+
+	problem, err := decoded.NewProblem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !problem.Minimization() {
+		t.Error("the sphere function has to be minimized")
+	}
+}
+
+func TestBitPPeaksProblemMatchesPPeaks(t *testing.T) {
+	parameters := ProblemParameters{ChromosomeSize: 130, PeaksNumber: 16, RandomSeed: 42}
+	var populations [2][]*Individual
+	for i, name := range []string{"ppeaks", "ppeaks-bits"} {
+		problem, err := NewProblem(name, parameters)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		engine := &Engine{
+			PopulationSize:    20,
+			GenerationsNumber: 10,
+			Seed:              1,
+			Minimization:      problem.Minimization(),
+			Operators:         ProblemOperators(problem, 2, 0.9, 0.05),
+			Evaluator:         &SequentialEvaluator{FitnessFunction: problem.Evaluate},
+		}
+		if populations[i], err = engine.Run(); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+	}
+
+	// The same seed evolves the same bits with the same fitness values.
+	for i, individual := range populations[1] {
+		expected := populations[0][i]
+		if actual := individual.Chromosome.(BitVectorChromosome).Bytes(parameters.ChromosomeSize); !reflect.DeepEqual(actual, expected.Chromosome) {
+			t.Errorf("expected %v, got %v", expected.Chromosome, actual)
+		}
+		if individual.FitnessValue != expected.FitnessValue {
+			t.Errorf("expected %v, got %v", expected.FitnessValue, individual.FitnessValue)
+		}
+	}
+
+	byteData, _ := EncodeIndividuals(populations[0])
+	bitData, _ := EncodeIndividuals(populations[1])
+	if len(bitData) >= len(byteData) {
+		t.Errorf("expected fewer than %v bytes, got %v", len(byteData), len(bitData))
+	}
+}
@@ -0,0 +1,240 @@
+package ga
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+func init() {
+	RegisterProblem("sphere", newFloat64Problem(SphereFunctionFitnessEvaluation, SphereFunctionMinBound, SphereFunctionMaxBound))
+	RegisterProblem("rastrigin", newFloat64Problem(RastriginFunctionFitnessEvaluation, RastriginFunctionMinBound, RastriginFunctionMaxBound))
+	RegisterProblem("ackley", newFloat64Problem(AckleyFunctionFitnessEvaluation, AckleyFunctionMinBound, AckleyFunctionMaxBound))
+	RegisterProblem("schwefel", newFloat64Problem(SchwefelFunctionFitnessEvaluation, SchwefelFunctionMinBound, SchwefelFunctionMaxBound))
+	RegisterProblem("rosenbrock", newFloat64Problem(RosenbrockFunctionFitnessEvaluation, RosenbrockFunctionMinBound, RosenbrockFunctionMaxBound))
+	RegisterProblem("griewank", newFloat64Problem(GriewankFunctionFitnessEvaluation, GriewankFunctionMinBound, GriewankFunctionMaxBound))
+	RegisterProblem("levy", newFloat64Problem(LevyFunctionFitnessEvaluation, LevyFunctionMinBound, LevyFunctionMaxBound))
+	RegisterProblem("zakharov", newFloat64Problem(ZakharovFunctionFitnessEvaluation, ZakharovFunctionMinBound, ZakharovFunctionMaxBound))
+	RegisterProblem("styblinski-tang", newFloat64Problem(StyblinskiTangFunctionFitnessEvaluation, StyblinskiTangFunctionMinBound, StyblinskiTangFunctionMaxBound))
+	RegisterProblem("michalewicz", newFloat64Problem(MichalewiczFunctionFitnessEvaluation, MichalewiczFunctionMinBound, MichalewiczFunctionMaxBound))
+	RegisterProblem("weierstrass", newFloat64Problem(WeierstrassFunctionFitnessEvaluation, WeierstrassFunctionMinBound, WeierstrassFunctionMaxBound))
+	RegisterProblem("schaffer-f6", newFloat64Problem(SchafferF6FunctionFitnessEvaluation, SchafferF6FunctionMinBound, SchafferF6FunctionMaxBound))
+	RegisterProblem("schaffer-f7", newFloat64Problem(SchafferF7FunctionFitnessEvaluation, SchafferF7FunctionMinBound, SchafferF7FunctionMaxBound))
+	RegisterProblem("bent-cigar", newFloat64Problem(BentCigarFunctionFitnessEvaluation, BentCigarFunctionMinBound, BentCigarFunctionMaxBound))
+	RegisterProblem("katsuura", newFloat64Problem(KatsuuraFunctionFitnessEvaluation, KatsuuraFunctionMinBound, KatsuuraFunctionMaxBound))
+	RegisterProblem("happy-cat", newFloat64Problem(HappyCatFunctionFitnessEvaluation, HappyCatFunctionMinBound, HappyCatFunctionMaxBound))
+	RegisterProblem("ppeaks", newPPeaksProblem)
+	RegisterContextProblem("ppeaks", newPPeaksProblemFromContext)
+	RegisterProblem("ppeaks-bits", newBitPPeaksProblem)
+	RegisterContextProblem("ppeaks-bits", newBitPPeaksProblemFromContext)
+	RegisterProblem("sleep", newSleepProblem)
+}
+
+// Minimization of a continuous function over a float64 vector.
+type Float64Problem struct {
+	FitnessFunction func(vector Float64VectorChromosome) Float64FitnessValue
+	ChromosomeSize  int
+	MinBound        float64
+	MaxBound        float64
+}
+
+func newFloat64Problem(fitnessFunction func(vector Float64VectorChromosome) Float64FitnessValue, minBound float64, maxBound float64) ProblemFactory {
+	return func(parameters ProblemParameters) (Problem, error) {
+		return &Float64Problem{
+			FitnessFunction: fitnessFunction,
+			ChromosomeSize:  parameters.ChromosomeSize,
+			MinBound:        minBound,
+			MaxBound:        maxBound,
+		}, nil
+	}
+}
+
+func (problem *Float64Problem) NewChromosome(random *rand.Rand) Chromosome {
+	return Float64VectorChromosomeInitialization(random, problem.ChromosomeSize, problem.MinBound, problem.MaxBound)
+}
+
+func (problem *Float64Problem) Bounds() (min, max interface{}) {
+	return problem.MinBound, problem.MaxBound
+}
+
+func (problem *Float64Problem) Evaluate(individual *Individual) FitnessValue {
+	return problem.FitnessFunction(individual.Chromosome.(Float64VectorChromosome))
+}
+
+func (problem *Float64Problem) Minimization() bool {
+	return true
+}
+
+func (problem *Float64Problem) Crossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	return TwoPointsCrossover(random, parent1, parent2, crossoverRate)
+}
+
+func (problem *Float64Problem) Mutation(random *rand.Rand, individual *Individual, mutationRate float64) {
+	Float64RandomMutation(random, individual, problem.MinBound, problem.MaxBound, mutationRate)
+}
+
+// Maximization of the P-Peaks function.
+type PPeaksProblem struct {
+	ChromosomeSize int
+	Peaks          []ByteVectorChromosome
+}
+
+// Generates the peaks from the random seed.
+func newPPeaksProblem(parameters ProblemParameters) (Problem, error) {
+	random := NewRandom(parameters.RandomSeed, ProblemStream)
+	peaks := make([]ByteVectorChromosome, parameters.PeaksNumber)
+	for i := int64(0); i < parameters.PeaksNumber; i++ {
+		peaks[i] = ByteVectorChromosomeInitialization(random, parameters.ChromosomeSize, PPeaksFunctionMinBound, PPeaksFunctionMaxBound)
+	}
+
+	return &PPeaksProblem{
+		ChromosomeSize: parameters.ChromosomeSize,
+		Peaks:          peaks,
+	}, nil
+}
+
+func newPPeaksProblemFromContext(parameters ProblemParameters, data interface{}) (Problem, error) {
+	peaks, ok := data.([]ByteVectorChromosome)
+	if !ok {
+		return nil, fmt.Errorf("invalid P-Peaks context %T", data)
+	}
+
+	return &PPeaksProblem{
+		ChromosomeSize: parameters.ChromosomeSize,
+		Peaks:          peaks,
+	}, nil
+}
+
+// Returns the peaks.
+func (problem *PPeaksProblem) Context() interface{} {
+	return problem.Peaks
+}
+
+func (problem *PPeaksProblem) NewChromosome(random *rand.Rand) Chromosome {
+	return ByteVectorChromosomeInitialization(random, problem.ChromosomeSize, PPeaksFunctionMinBound, PPeaksFunctionMaxBound)
+}
+
+func (problem *PPeaksProblem) Bounds() (min, max interface{}) {
+	return byte(PPeaksFunctionMinBound), byte(PPeaksFunctionMaxBound)
+}
+
+func (problem *PPeaksProblem) Evaluate(individual *Individual) FitnessValue {
+	return PPeaksFitnessFunction(individual.Chromosome.(ByteVectorChromosome), problem.Peaks)
+}
+
+func (problem *PPeaksProblem) Minimization() bool {
+	return false
+}
+
+func (problem *PPeaksProblem) Crossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	return TwoPointsCrossover(random, parent1, parent2, crossoverRate)
+}
+
+func (problem *PPeaksProblem) Mutation(random *rand.Rand, individual *Individual, mutationRate float64) {
+	ByteRandomMutation(random, individual, PPeaksFunctionMinBound, PPeaksFunctionMaxBound, mutationRate)
+}
+
+// Maximization of the P-Peaks function over bit-packed chromosomes. The peaks,
+// the operators and so the fitness values are the same as the byte version, but
+// the evaluation is faster and the messages are smaller.
+type BitPPeaksProblem struct {
+	ChromosomeSize int
+	Peaks          []BitVectorChromosome
+}
+
+// Generates the peaks from the random seed.
+func newBitPPeaksProblem(parameters ProblemParameters) (Problem, error) {
+	random := NewRandom(parameters.RandomSeed, ProblemStream)
+	peaks := make([]BitVectorChromosome, parameters.PeaksNumber)
+	for i := int64(0); i < parameters.PeaksNumber; i++ {
+		peaks[i] = BitVectorChromosomeInitialization(random, parameters.ChromosomeSize)
+	}
+
+	return &BitPPeaksProblem{
+		ChromosomeSize: parameters.ChromosomeSize,
+		Peaks:          peaks,
+	}, nil
+}
+
+func newBitPPeaksProblemFromContext(parameters ProblemParameters, data interface{}) (Problem, error) {
+	peaks, ok := data.([]BitVectorChromosome)
+	if !ok {
+		return nil, fmt.Errorf("invalid P-Peaks context %T", data)
+	}
+
+	return &BitPPeaksProblem{
+		ChromosomeSize: parameters.ChromosomeSize,
+		Peaks:          peaks,
+	}, nil
+}
+
+// Returns the peaks.
+func (problem *BitPPeaksProblem) Context() interface{} {
+	return problem.Peaks
+}
+
+func (problem *BitPPeaksProblem) NewChromosome(random *rand.Rand) Chromosome {
+	return BitVectorChromosomeInitialization(random, problem.ChromosomeSize)
+}
+
+func (problem *BitPPeaksProblem) Bounds() (min, max interface{}) {
+	return byte(PPeaksFunctionMinBound), byte(PPeaksFunctionMaxBound)
+}
+
+func (problem *BitPPeaksProblem) Evaluate(individual *Individual) FitnessValue {
+	return BitPPeaksFitnessFunction(individual.Chromosome.(BitVectorChromosome), problem.Peaks, problem.ChromosomeSize)
+}
+
+func (problem *BitPPeaksProblem) Minimization() bool {
+	return false
+}
+
+func (problem *BitPPeaksProblem) Crossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	return BitTwoPointsCrossover(random, parent1, parent2, problem.ChromosomeSize, crossoverRate)
+}
+
+func (problem *BitPPeaksProblem) Mutation(random *rand.Rand, individual *Individual, mutationRate float64) {
+	BitRandomMutation(random, individual, problem.ChromosomeSize, mutationRate)
+}
+
+// Sleeps for a fixed time and returns a random fitness value, to simulate an
+// expensive fitness function. The fitness value is drawn from the random seed
+// and the individual id, so that it does not depend on the evaluating node.
+type SleepProblem struct {
+	ChromosomeSize int
+	Duration       time.Duration
+	RandomSeed     int64
+}
+
+func newSleepProblem(parameters ProblemParameters) (Problem, error) {
+	return &SleepProblem{
+		ChromosomeSize: parameters.ChromosomeSize,
+		Duration:       time.Duration(parameters.SleepTime) * time.Nanosecond,
+		RandomSeed:     parameters.RandomSeed,
+	}, nil
+}
+
+func (problem *SleepProblem) NewChromosome(random *rand.Rand) Chromosome {
+	return ByteVectorChromosomeInitialization(random, problem.ChromosomeSize, 0, 1)
+}
+
+func (problem *SleepProblem) Bounds() (min, max interface{}) {
+	return byte(0), byte(1)
+}
+
+func (problem *SleepProblem) Evaluate(individual *Individual) FitnessValue {
+	SleepFitnessFunction(problem.Duration)
+	return Float64FitnessValue(NewRandom(problem.RandomSeed, EvaluationStream, individual.Id).Float64())
+}
+
+func (problem *SleepProblem) Minimization() bool {
+	return false
+}
+
+func (problem *SleepProblem) Crossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	return TwoPointsCrossover(random, parent1, parent2, crossoverRate)
+}
+
+// The chromosome is not relevant to the fitness value, so it is never mutated.
+func (problem *SleepProblem) Mutation(random *rand.Rand, individual *Individual, mutationRate float64) {
+}
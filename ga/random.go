@@ -0,0 +1,35 @@
+package ga
+
+import (
+	"math/rand"
+)
+
+// Streams of random numbers, so that the stochastic components drawing with the
+// same keys do not share their numbers.
+const (
+	ProblemStream = int64(iota)
+	InitializationStream
+	BreedingStream
+	EvaluationStream
+	MigrationStream
+)
+
+// Creates the generator of a stream, derived from the experiment seed and the
+// keys, such as the generation and the individual id. The same seed, stream and
+// keys always give the same numbers, wherever and whenever they are drawn.
+func NewRandom(seed int64, stream int64, keys ...int64) *rand.Rand {
+	state := mix(uint64(seed))
+	state = mix(state ^ uint64(stream))
+	for _, key := range keys {
+		state = mix(state ^ uint64(key))
+	}
+	return rand.New(rand.NewSource(int64(state)))
+}
+
+// Scrambles the bits of a value with the SplitMix64 finalizer.
+func mix(value uint64) uint64 {
+	value += 0x9e3779b97f4a7c15
+	value = (value ^ (value >> 30)) * 0xbf58476d1ce4e5b9
+	value = (value ^ (value >> 27)) * 0x94d049bb133111eb
+	return value ^ (value >> 31)
+}
@@ -0,0 +1,25 @@
+package ga
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestNewRandom(t *testing.T) {
+	if NewRandom(1, BreedingStream, 2, 3).Int63() != NewRandom(1, BreedingStream, 2, 3).Int63() {
+		t.Error("the same seed, stream and keys have to give the same numbers")
+	}
+
+	// Changing any part of the derivation changes the numbers.
+	expected := NewRandom(1, BreedingStream, 2, 3).Int63()
+	for _, random := range []*rand.Rand{
+		NewRandom(2, BreedingStream, 2, 3),
+		NewRandom(1, InitializationStream, 2, 3),
+		NewRandom(1, BreedingStream, 3, 2),
+		NewRandom(1, BreedingStream, 2),
+	} {
+		if random.Int63() == expected {
+			t.Error("different derivations have to give different numbers")
+		}
+	}
+}
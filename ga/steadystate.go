@@ -0,0 +1,262 @@
+package ga
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Evaluates individuals asynchronously: each submitted individual is sent back
+// on the results channel once evaluated, in any order. Err returns the error
+// that closed the results channel, if any.
+type AsyncEvaluator interface {
+	Submit(individual *Individual) error
+	Results() <-chan *Individual
+	Err() error
+}
+
+// Evaluates the submitted individuals immediately in the current process.
+type SequentialAsyncEvaluator struct {
+	FitnessFunction func(individual *Individual) FitnessValue
+	results         chan *Individual
+}
+
+// Creates an evaluator holding up to capacity evaluated individuals not yet received.
+func NewSequentialAsyncEvaluator(fitnessFunction func(individual *Individual) FitnessValue, capacity int) *SequentialAsyncEvaluator {
+	return &SequentialAsyncEvaluator{
+		FitnessFunction: fitnessFunction,
+		results:         make(chan *Individual, capacity),
+	}
+}
+
+func (evaluator *SequentialAsyncEvaluator) Submit(individual *Individual) error {
+	individual.FitnessValue = evaluator.FitnessFunction(individual)
+	select {
+	case evaluator.results <- individual:
+		return nil
+	default:
+		return fmt.Errorf("too many individuals in flight")
+	}
+}
+
+func (evaluator *SequentialAsyncEvaluator) Results() <-chan *Individual {
+	return evaluator.results
+}
+
+func (evaluator *SequentialAsyncEvaluator) Err() error {
+	return nil
+}
+
+// Evaluates the submitted individuals with a pool of goroutines in the current
+// process, sending them back in the order they finish.
+type ParallelAsyncEvaluator struct {
+	FitnessFunction func(individual *Individual) FitnessValue
+	individuals     chan *Individual
+	results         chan *Individual
+}
+
+// Creates an evaluator with workersNumber goroutines, holding up to capacity
+// individuals in flight. The goroutines stop when the evaluator is closed.
+func NewParallelAsyncEvaluator(fitnessFunction func(individual *Individual) FitnessValue, workersNumber int, capacity int) *ParallelAsyncEvaluator {
+	evaluator := &ParallelAsyncEvaluator{
+		FitnessFunction: fitnessFunction,
+		individuals:     make(chan *Individual, capacity),
+		results:         make(chan *Individual, capacity),
+	}
+	if workersNumber < 1 {
+		workersNumber = 1
+	}
+	for i := 0; i < workersNumber; i++ {
+		go evaluator.work()
+	}
+	return evaluator
+}
+
+func (evaluator *ParallelAsyncEvaluator) work() {
+	for individual := range evaluator.individuals {
+		individual.FitnessValue = evaluator.FitnessFunction(individual)
+		evaluator.results <- individual
+	}
+}
+
+func (evaluator *ParallelAsyncEvaluator) Submit(individual *Individual) error {
+	select {
+	case evaluator.individuals <- individual:
+		return nil
+	default:
+		return fmt.Errorf("too many individuals in flight")
+	}
+}
+
+func (evaluator *ParallelAsyncEvaluator) Results() <-chan *Individual {
+	return evaluator.results
+}
+
+func (evaluator *ParallelAsyncEvaluator) Err() error {
+	return nil
+}
+
+// Stops the goroutines once the submitted individuals are evaluated.
+func (evaluator *ParallelAsyncEvaluator) Close() {
+	close(evaluator.individuals)
+}
+
+// Runs a steady-state genetic algorithm with asynchronous evaluations: the
+// evaluator is kept busy with InFlightNumber individuals, and every evaluated
+// individual immediately triggers the breeding of a new one. An offspring
+// replaces the worst individual of the population if it is better. The run
+// ends after EvaluationsNumber evaluations. Each individual is created with the
+// generator of its id, but the population it is bred from depends on the order
+// of the evaluations.
+type SteadyStateEngine struct {
+	PopulationSize    int
+	EvaluationsNumber int64
+	InFlightNumber    int
+	Minimization      bool
+	Operators         Operators
+	Evaluator         AsyncEvaluator
+
+	// Derives the random generators of the individuals.
+	Seed int64
+
+	// The generation passed to the hooks counts the evaluations in units of
+	// population size.
+	Hooks Hooks
+}
+
+// Runs the evaluations and returns the final population.
+func (engine *SteadyStateEngine) Run() ([]*Individual, error) {
+	inFlightNumber := engine.InFlightNumber
+	if inFlightNumber <= 0 || inFlightNumber > engine.PopulationSize {
+		inFlightNumber = engine.PopulationSize
+	}
+
+	population := make([]*Individual, 0, engine.PopulationSize)
+	submitted := int64(0)
+	evaluated := int64(0)
+	inFlight := 0
+
+	log.Info("Steady-state evolution started")
+	startTime := time.Now()
+	generationStartTime := startTime
+
+	// Submits new individuals until the in-flight limit, the evaluation
+	// budget or the end of the initial population is reached.
+	submit := func() error {
+		for inFlight < inFlightNumber && submitted < engine.EvaluationsNumber {
+			individual := new(Individual)
+			individual.Id = submitted
+			individual.Generation = submitted / int64(engine.PopulationSize)
+
+			switch {
+			case submitted < int64(engine.PopulationSize):
+				individual.Chromosome = engine.Operators.Initialization(NewRandom(engine.Seed, InitializationStream, submitted))
+			case len(population) == engine.PopulationSize:
+				random := NewRandom(engine.Seed, BreedingStream, individual.Generation, submitted)
+				individual.Chromosome = engine.breed(random, population).Chromosome
+			default:
+				// Waits for the initial population to be complete.
+				return nil
+			}
+
+			if err := engine.Evaluator.Submit(individual); err != nil {
+				return err
+			}
+			submitted++
+			inFlight++
+		}
+		return nil
+	}
+
+	if err := submit(); err != nil {
+		return nil, err
+	}
+
+	for inFlight > 0 {
+		individual, ok := <-engine.Evaluator.Results()
+		if !ok {
+			if err := engine.Evaluator.Err(); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("evaluator closed with %v individuals in flight", inFlight)
+		}
+		inFlight--
+		evaluated++
+
+		engine.insert(&population, individual)
+
+		// Reports every population size evaluations.
+		if evaluated%int64(engine.PopulationSize) == 0 {
+			generation := evaluated/int64(engine.PopulationSize) - 1
+			if generation == 0 && engine.Hooks.PhaseFinished != nil {
+				engine.Hooks.PhaseFinished(InitializationPhase, 0, time.Since(startTime))
+			}
+
+			log.Infof("Finished generation %v", generation)
+			if engine.Hooks.PhaseFinished != nil {
+				engine.Hooks.PhaseFinished(GenerationPhase, generation, time.Since(generationStartTime))
+			}
+			if engine.Hooks.PopulationEvaluated != nil {
+				engine.Hooks.PopulationEvaluated(generation, population, false)
+			}
+			generationStartTime = time.Now()
+		}
+
+		if err := submit(); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Info("Steady-state evolution finished")
+	if len(population) > 0 && engine.Hooks.PopulationEvaluated != nil {
+		engine.Hooks.PopulationEvaluated(evaluated/int64(engine.PopulationSize), population, true)
+	}
+
+	return population, nil
+}
+
+// Breeds a single offspring from the population.
+func (engine *SteadyStateEngine) breed(random *rand.Rand, population []*Individual) *Individual {
+	parent1 := engine.Operators.Selection(random, population)
+	parent2 := engine.Operators.Selection(random, population)
+
+	child, _ := engine.Operators.Crossover(random, parent1, parent2)
+	child.Chromosome = CloneChromosome(child.Chromosome)
+	child.FitnessValue = nil
+
+	if engine.Operators.Mutation != nil {
+		engine.Operators.Mutation(random, &child)
+	}
+
+	return &child
+}
+
+// Adds an evaluated individual to the population, replacing the worst one if
+// the population is complete and the individual is better.
+func (engine *SteadyStateEngine) insert(population *[]*Individual, individual *Individual) {
+	if len(*population) < engine.PopulationSize {
+		*population = append(*population, individual)
+		return
+	}
+
+	worst := 0
+	for j, other := range *population {
+		if engine.better((*population)[worst], other) {
+			worst = j
+		}
+	}
+
+	if engine.better(individual, (*population)[worst]) {
+		(*population)[worst] = individual
+	}
+}
+
+// Tells if an individual is strictly better than another one.
+func (engine *SteadyStateEngine) better(individual, other *Individual) bool {
+	if engine.Minimization {
+		return individual.FitnessValue.Less(other.FitnessValue)
+	}
+	return other.FitnessValue.Less(individual.FitnessValue)
+}
@@ -0,0 +1,146 @@
+package ga
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSteadyStateEngine(t *testing.T) {
+	problem, _ := NewProblem("sphere", ProblemParameters{ChromosomeSize: 10})
+
+	evaluations := 0
+	fitnessFunction := func(individual *Individual) FitnessValue {
+		evaluations++
+		return problem.Evaluate(individual)
+	}
+
+	engine := &SteadyStateEngine{
+		PopulationSize:    20,
+		EvaluationsNumber: 500,
+		InFlightNumber:    5,
+		Minimization:      problem.Minimization(),
+		Operators:         ProblemOperators(problem, 2, 1.0, 0.1),
+		Evaluator:         NewSequentialAsyncEvaluator(fitnessFunction, 5),
+	}
+
+	var bestFitnessValue FitnessValue
+	reports := 0
+	engine.Hooks.PopulationEvaluated = func(generation int64, population []*Individual, solution bool) {
+		if len(population) != engine.PopulationSize {
+			t.Fatalf("expected %v individuals, got %v", engine.PopulationSize, len(population))
+		}
+		best, _, _ := Statistics(population, true)
+		if bestFitnessValue != nil && bestFitnessValue.Less(best.FitnessValue) {
+			t.Errorf("generation %v: best fitness regressed from %v to %v", generation, bestFitnessValue, best.FitnessValue)
+		}
+		bestFitnessValue = best.FitnessValue
+		reports++
+	}
+
+	population, err := engine.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if evaluations != int(engine.EvaluationsNumber) {
+		t.Errorf("expected %v evaluations, got %v", engine.EvaluationsNumber, evaluations)
+	}
+	if expected := int(engine.EvaluationsNumber)/engine.PopulationSize + 1; reports != expected {
+		t.Errorf("expected %v reports, got %v", expected, reports)
+	}
+	for _, individual := range population {
+		if individual.FitnessValue == nil {
+			t.Fatalf("individual %v not evaluated", individual.Id)
+		}
+	}
+}
+
+func TestSteadyStateEngineTooManyInFlight(t *testing.T) {
+	problem, _ := NewProblem("sphere", ProblemParameters{ChromosomeSize: 10})
+
+	engine := &SteadyStateEngine{
+		PopulationSize:    10,
+		EvaluationsNumber: 100,
+		InFlightNumber:    4,
+		Minimization:      problem.Minimization(),
+		Operators:         ProblemOperators(problem, 2, 1.0, 0.1),
+		Evaluator:         NewSequentialAsyncEvaluator(problem.Evaluate, 2),
+	}
+
+	if _, err := engine.Run(); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestSteadyStateEngineParallelEvaluator(t *testing.T) {
+	problem, _ := NewProblem("sphere", ProblemParameters{ChromosomeSize: 10})
+
+	var evaluations int64
+	fitnessFunction := func(individual *Individual) FitnessValue {
+		atomic.AddInt64(&evaluations, 1)
+		return problem.Evaluate(individual)
+	}
+	evaluator := NewParallelAsyncEvaluator(fitnessFunction, 4, 8)
+	defer evaluator.Close()
+
+	engine := &SteadyStateEngine{
+		PopulationSize:    20,
+		EvaluationsNumber: 500,
+		InFlightNumber:    8,
+		Minimization:      problem.Minimization(),
+		Operators:         ProblemOperators(problem, 2, 1.0, 0.1),
+		Evaluator:         evaluator,
+	}
+
+	population, err := engine.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evaluations != engine.EvaluationsNumber {
+		t.Errorf("expected %v evaluations, got %v", engine.EvaluationsNumber, evaluations)
+	}
+	if len(population) != engine.PopulationSize {
+		t.Errorf("expected %v individuals, got %v", engine.PopulationSize, len(population))
+	}
+}
+
+// Closes its results with an error as soon as an individual is submitted.
+type failingAsyncEvaluator struct {
+	results chan *Individual
+	err     error
+}
+
+func (evaluator *failingAsyncEvaluator) Submit(individual *Individual) error {
+	if evaluator.err == nil {
+		evaluator.err = errors.New("broker unreachable")
+		close(evaluator.results)
+	}
+	return nil
+}
+
+func (evaluator *failingAsyncEvaluator) Results() <-chan *Individual {
+	return evaluator.results
+}
+
+func (evaluator *failingAsyncEvaluator) Err() error {
+	return evaluator.err
+}
+
+func TestSteadyStateEngineEvaluatorError(t *testing.T) {
+	problem, _ := NewProblem("sphere", ProblemParameters{ChromosomeSize: 10})
+	evaluator := &failingAsyncEvaluator{results: make(chan *Individual)}
+
+	engine := &SteadyStateEngine{
+		PopulationSize:    10,
+		EvaluationsNumber: 100,
+		InFlightNumber:    4,
+		Minimization:      problem.Minimization(),
+		Operators:         ProblemOperators(problem, 2, 1.0, 0.1),
+		Evaluator:         evaluator,
+	}
+
+	if _, err := engine.Run(); err != evaluator.err {
+		t.Errorf("expected the error of the evaluator, got %v", err)
+	}
+}
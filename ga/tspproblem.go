@@ -0,0 +1,140 @@
+package ga
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/pasqualesalza/amqpga/ga/data/tsp"
+)
+
+func init() {
+	RegisterProblem("tsp", newTSPProblem)
+	RegisterContextProblem("tsp", newTSPProblemFromContext)
+}
+
+// Crossover operators for permutations, by name.
+var PermutationCrossoverOperators = map[string]func(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual){
+	"ox":  OrderCrossover,
+	"pmx": PartiallyMappedCrossover,
+	"cx":  CycleCrossover,
+}
+
+// Mutation operators for permutations, by name.
+var PermutationMutationOperators = map[string]func(random *rand.Rand, individual *Individual, mutationRate float64){
+	"swap":      SwapMutation,
+	"inversion": InversionMutation,
+}
+
+const (
+	DefaultTSPInstance          = "a280"
+	DefaultPermutationCrossover = "ox"
+	DefaultPermutationMutation  = "inversion"
+)
+
+// Minimization of the tour length of a symmetric travelling salesman problem,
+// with permutation chromosomes.
+type TSPProblem struct {
+	Instance          *tsp.Instance
+	CrossoverOperator func(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual)
+	MutationOperator  func(random *rand.Rand, individual *Individual, mutationRate float64)
+}
+
+func newTSPProblem(parameters ProblemParameters) (Problem, error) {
+	instance, err := tspInstance(parameters)
+	if err != nil {
+		return nil, err
+	}
+	return newTSPProblemFromContext(parameters, instance)
+}
+
+// Builds the problem of an instance already loaded.
+func newTSPProblemFromContext(parameters ProblemParameters, data interface{}) (Problem, error) {
+	instance, ok := data.(*tsp.Instance)
+	if !ok {
+		return nil, fmt.Errorf("invalid TSP context %T", data)
+	}
+
+	crossoverOperator, mutationOperator, err := permutationOperators(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TSPProblem{
+		Instance:          instance,
+		CrossoverOperator: crossoverOperator,
+		MutationOperator:  mutationOperator,
+	}, nil
+}
+
+// Loads the instance file or looks up the compiled-in instance selected by the parameters.
+func tspInstance(parameters ProblemParameters) (*tsp.Instance, error) {
+	if parameters.InstanceFile != "" {
+		instance, err := tsp.Load(parameters.InstanceFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the TSP instance %v: %v", parameters.InstanceFile, err)
+		}
+		return instance, nil
+	}
+
+	instanceName := parameters.InstanceName
+	if instanceName == "" {
+		instanceName = DefaultTSPInstance
+	}
+	nodes, ok := tsp.Instances[instanceName]
+	if !ok {
+		return nil, fmt.Errorf("unknown TSP instance %v", instanceName)
+	}
+	return tsp.NewEuclideanInstance(instanceName, nodes), nil
+}
+
+// Looks up the permutation operators selected by the parameters.
+func permutationOperators(parameters ProblemParameters) (func(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual), func(random *rand.Rand, individual *Individual, mutationRate float64), error) {
+	crossoverName := parameters.CrossoverOperator
+	if crossoverName == "" {
+		crossoverName = DefaultPermutationCrossover
+	}
+	crossoverOperator, ok := PermutationCrossoverOperators[crossoverName]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown permutation crossover operator %v", crossoverName)
+	}
+
+	mutationName := parameters.MutationOperator
+	if mutationName == "" {
+		mutationName = DefaultPermutationMutation
+	}
+	mutationOperator, ok := PermutationMutationOperators[mutationName]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown permutation mutation operator %v", mutationName)
+	}
+
+	return crossoverOperator, mutationOperator, nil
+}
+
+// Returns the instance.
+func (problem *TSPProblem) Context() interface{} {
+	return problem.Instance
+}
+
+func (problem *TSPProblem) NewChromosome(random *rand.Rand) Chromosome {
+	return PermutationInitialization(random, problem.Instance.Dimension)
+}
+
+func (problem *TSPProblem) Bounds() (min, max interface{}) {
+	return 0, problem.Instance.Dimension - 1
+}
+
+func (problem *TSPProblem) Evaluate(individual *Individual) FitnessValue {
+	return TourLengthFitnessFunction(individual.Chromosome.(IntVectorChromosome), problem.Instance)
+}
+
+func (problem *TSPProblem) Minimization() bool {
+	return true
+}
+
+func (problem *TSPProblem) Crossover(random *rand.Rand, parent1, parent2 *Individual, crossoverRate float64) (Individual, Individual) {
+	return problem.CrossoverOperator(random, parent1, parent2, crossoverRate)
+}
+
+func (problem *TSPProblem) Mutation(random *rand.Rand, individual *Individual, mutationRate float64) {
+	problem.MutationOperator(random, individual, mutationRate)
+}
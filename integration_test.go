@@ -0,0 +1,394 @@
+package main
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pasqualesalza/amqpga/communication"
+	"github.com/pasqualesalza/amqpga/ga"
+	"github.com/pasqualesalza/amqpga/transport"
+)
+
+// The settings of the experiments run by the integration tests.
+const (
+	testSeed        = int64(7)
+	testPopulation  = 16
+	testGenerations = 4
+	testBatchSize   = 3
+	testSlaves      = 3
+	testWorkers     = 2
+)
+
+// Returns the descriptor of a problem of the integration tests.
+func newTestDescriptor(name string) *ga.ProblemDescriptor {
+	return &ga.ProblemDescriptor{
+		Name: name,
+		Parameters: ga.ProblemParameters{
+			ChromosomeSize: 16,
+			PeaksNumber:    8,
+			RandomSeed:     testSeed,
+		},
+	}
+}
+
+// Runs the experiment with the evaluator.
+func runTestEngine(t *testing.T, problem ga.Problem, evaluator ga.Evaluator) []*ga.Individual {
+	engine := &ga.Engine{
+		PopulationSize:    testPopulation,
+		GenerationsNumber: testGenerations,
+		ElitesNumber:      1,
+		Minimization:      problem.Minimization(),
+		Operators:         ga.ProblemOperators(problem, 2, 0.9, 0.05),
+		Evaluator:         evaluator,
+		Seed:              testSeed,
+	}
+	population, err := engine.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return population
+}
+
+// Runs the experiment with the sequential role.
+func runSequential(t *testing.T, descriptor *ga.ProblemDescriptor) []*ga.Individual {
+	problem, err := descriptor.NewProblem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runTestEngine(t, problem, &ga.SequentialEvaluator{FitnessFunction: problem.Evaluate})
+}
+
+// Serves the experiments through the transport as a slave with the workers,
+// until the transport closes. Returns the function waiting for the slave to
+// stop.
+func startTestSlave(t *testing.T, masters transport.Transport, workersNumber int) func() {
+	problems := newProblemCache(masters.RequestContext, time.Second, 50*time.Millisecond)
+	contexts, err := masters.Contexts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go receiveProblemContexts(problems, contexts)
+
+	stopped := make(chan bool)
+	go func() {
+		defer close(stopped)
+		serveAnnouncedExperiments(problems, masters, workersNumber)
+	}()
+	return func() { <-stopped }
+}
+
+// Runs the experiment with the master role, publishing the tasks through the
+// transport to the slaves. The timeout and the retries recover the lost tasks.
+func runMaster(t *testing.T, master transport.Transport, descriptor *ga.ProblemDescriptor, codec ga.Codec, timeout time.Duration) []*ga.Individual {
+	problem, err := descriptor.NewProblem()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contextHash, finish, err := serveExperiment(master, "test", descriptor, problem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer finish()
+
+	publisher, err := newRequestPublisher(master, "test", contextHash, codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := newEvaluationTracker(timeout, 10, failTimeoutPolicy, problem)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return runTestEngine(t, problem, &masterEvaluator{
+		publisher:     publisher,
+		responses:     publisher.responses,
+		reconnections: master.Reconnections(),
+		batchSizer:    communication.NewBatchSizer(testBatchSize, 0, false),
+		tracker:       tracker,
+	})
+}
+
+// Runs the experiment with a master and the slaves sharing an in-process
+// transport, publishing through the wrapper.
+func runInProcess(t *testing.T, descriptor *ga.ProblemDescriptor, slavesNumber int, codec ga.Codec, timeout time.Duration, wrap func(transport.Transport) transport.Transport) ([]*ga.Individual, *transport.InProcessTransport) {
+	shared := transport.NewInProcessTransport()
+	defer shared.Close()
+
+	slaves := make([]func(), slavesNumber)
+	for i := range slaves {
+		slaves[i] = startTestSlave(t, wrap(shared), testWorkers)
+	}
+
+	population := runMaster(t, wrap(shared), descriptor, codec, timeout)
+
+	shared.Close()
+	for _, wait := range slaves {
+		wait()
+	}
+	return population, shared
+}
+
+// Checks that the populations have the same individuals with the same fitness.
+func assertSamePopulation(t *testing.T, expected []*ga.Individual, actual []*ga.Individual) {
+	if len(expected) != len(actual) {
+		t.Fatalf("expected %v individuals, got %v", len(expected), len(actual))
+	}
+	for i := range expected {
+		if expected[i].Id != actual[i].Id ||
+			!reflect.DeepEqual(expected[i].Chromosome, actual[i].Chromosome) ||
+			!reflect.DeepEqual(expected[i].FitnessValue, actual[i].FitnessValue) {
+			t.Fatalf("individual %v differs: expected %v, got %v", i, expected[i], actual[i])
+		}
+	}
+}
+
+func noFaults(wrapped transport.Transport) transport.Transport {
+	return wrapped
+}
+
+func TestMasterMatchesSequential(t *testing.T) {
+	descriptor := newTestDescriptor("ppeaks")
+	expected := runSequential(t, descriptor)
+	actual, _ := runInProcess(t, descriptor, testSlaves, ga.Codecs[ga.DefaultCodec], 0, noFaults)
+	assertSamePopulation(t, expected, actual)
+}
+
+func TestParallelMatchesSequential(t *testing.T) {
+	descriptor := newTestDescriptor("ppeaks")
+	expected := runSequential(t, descriptor)
+
+	problem, err := descriptor.NewProblem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := runTestEngine(t, problem, &ga.ParallelEvaluator{FitnessFunction: problem.Evaluate, WorkersNumber: 4})
+	assertSamePopulation(t, expected, actual)
+}
+
+func TestMasterMatchesSequentialWithEveryCodec(t *testing.T) {
+	// The float64 vectors are supported by every codec.
+	descriptor := newTestDescriptor("sphere")
+	expected := runSequential(t, descriptor)
+
+	for _, name := range ga.CodecNames() {
+		actual, _ := runInProcess(t, descriptor, testSlaves, ga.Codecs[name], 0, noFaults)
+		assertSamePopulation(t, expected, actual)
+	}
+}
+
+func TestMasterMatchesSequentialWithAnyClusterSize(t *testing.T) {
+	// The fitness values of the sleep problem are random too.
+	descriptor := newTestDescriptor("sleep")
+	expected := runSequential(t, descriptor)
+
+	for _, slavesNumber := range []int{1, 5} {
+		actual, _ := runInProcess(t, descriptor, slavesNumber, ga.Codecs[ga.DefaultCodec], 0, noFaults)
+		assertSamePopulation(t, expected, actual)
+	}
+}
+
+func TestMasterMatchesSequentialOverTCP(t *testing.T) {
+	descriptor := newTestDescriptor("ppeaks")
+	expected := runSequential(t, descriptor)
+
+	server, err := transport.ListenTCP("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	slaves := make([]func(), testSlaves)
+	for i := range slaves {
+		client, err := transport.DialTCP(server.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+		slaves[i] = startTestSlave(t, client, testWorkers)
+	}
+
+	actual := runMaster(t, server, descriptor, ga.Codecs[ga.DefaultCodec], 0)
+	assertSamePopulation(t, expected, actual)
+
+	server.Close()
+	for _, wait := range slaves {
+		wait()
+	}
+}
+
+// The faults injected in the published messages.
+type fault int
+
+const (
+	noFault fault = iota
+	dropFault
+	duplicateFault
+	delayFault
+	corruptFault
+)
+
+// Injects a fault in the tasks and the results published through the
+// transports it wraps. A message is hit once, so that the experiment finishes.
+type faultInjector struct {
+	taskFault   fault
+	resultFault fault
+	// Injects the fault in a message out of every period.
+	period int
+	delay  time.Duration
+
+	mutex     sync.Mutex
+	published int
+	// The messages already hit, by kind and correlation id.
+	faulted map[string]bool
+}
+
+func newFaultInjector(taskFault fault, resultFault fault, period int, delay time.Duration) *faultInjector {
+	return &faultInjector{
+		taskFault:   taskFault,
+		resultFault: resultFault,
+		period:      period,
+		delay:       delay,
+		faulted:     make(map[string]bool),
+	}
+}
+
+// Returns the fault to inject in the message of the kind.
+func (injector *faultInjector) fault(kind string, injected fault, message *transport.Message) fault {
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+
+	key := kind + "/" + message.CorrelationId
+	if injected == noFault || injector.faulted[key] {
+		return noFault
+	}
+	injector.published++
+	if injector.published%injector.period != 0 {
+		return noFault
+	}
+	injector.faulted[key] = true
+	return injected
+}
+
+// Publishes the message with its fault.
+func (injector *faultInjector) inject(kind string, injected fault, message *transport.Message, publish func(message *transport.Message) error) error {
+	switch injector.fault(kind, injected, message) {
+	case dropFault:
+		return nil
+	case duplicateFault:
+		if err := publish(message); err != nil {
+			return err
+		}
+	case delayFault:
+		go func() {
+			time.Sleep(injector.delay)
+			publish(message)
+		}()
+		return nil
+	case corruptFault:
+		corrupted := *message
+		corrupted.Body = []byte("corrupted")
+		return publish(&corrupted)
+	}
+	return publish(message)
+}
+
+// Checks that some faults were injected.
+func (injector *faultInjector) assertInjected(t *testing.T) {
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+
+	if len(injector.faulted) == 0 {
+		t.Error("no fault injected")
+	}
+}
+
+func (injector *faultInjector) wrap(wrapped transport.Transport) transport.Transport {
+	return &faultyTransport{Transport: wrapped, injector: injector}
+}
+
+// A transport publishing the messages with the faults of the injector.
+type faultyTransport struct {
+	transport.Transport
+	injector *faultInjector
+}
+
+func (faulty *faultyTransport) PublishTask(experimentId string, task *transport.Message) error {
+	return faulty.injector.inject("task", faulty.injector.taskFault, task, func(message *transport.Message) error {
+		return faulty.Transport.PublishTask(experimentId, message)
+	})
+}
+
+func (faulty *faultyTransport) PublishResult(replyTo string, result *transport.Message) error {
+	return faulty.injector.inject("result", faulty.injector.resultFault, result, func(message *transport.Message) error {
+		return faulty.Transport.PublishResult(replyTo, message)
+	})
+}
+
+// The timeout of the individuals in the fault injection tests.
+const testTimeout = 100 * time.Millisecond
+
+func TestMasterRecoversFromDroppedTasks(t *testing.T) {
+	descriptor := newTestDescriptor("ppeaks")
+	expected := runSequential(t, descriptor)
+	injector := newFaultInjector(dropFault, noFault, 3, 0)
+	actual, _ := runInProcess(t, descriptor, testSlaves, ga.Codecs[ga.DefaultCodec], testTimeout, injector.wrap)
+	assertSamePopulation(t, expected, actual)
+	injector.assertInjected(t)
+}
+
+func TestMasterRecoversFromDroppedResults(t *testing.T) {
+	descriptor := newTestDescriptor("ppeaks")
+	expected := runSequential(t, descriptor)
+	injector := newFaultInjector(noFault, dropFault, 3, 0)
+	actual, _ := runInProcess(t, descriptor, testSlaves, ga.Codecs[ga.DefaultCodec], testTimeout, injector.wrap)
+	assertSamePopulation(t, expected, actual)
+	injector.assertInjected(t)
+}
+
+func TestMasterDiscardsDuplicatedMessages(t *testing.T) {
+	descriptor := newTestDescriptor("ppeaks")
+	expected := runSequential(t, descriptor)
+	injector := newFaultInjector(duplicateFault, duplicateFault, 2, 0)
+	actual, _ := runInProcess(t, descriptor, testSlaves, ga.Codecs[ga.DefaultCodec], 0, injector.wrap)
+	assertSamePopulation(t, expected, actual)
+	injector.assertInjected(t)
+}
+
+func TestMasterDiscardsDelayedResults(t *testing.T) {
+	descriptor := newTestDescriptor("ppeaks")
+	expected := runSequential(t, descriptor)
+	// The delayed results arrive after the individuals are published again.
+	injector := newFaultInjector(noFault, delayFault, 3, 3*testTimeout)
+	actual, _ := runInProcess(t, descriptor, testSlaves, ga.Codecs[ga.DefaultCodec], testTimeout, injector.wrap)
+	assertSamePopulation(t, expected, actual)
+	injector.assertInjected(t)
+}
+
+func TestSlavesRejectCorruptedTasks(t *testing.T) {
+	descriptor := newTestDescriptor("ppeaks")
+	expected := runSequential(t, descriptor)
+	injector := newFaultInjector(corruptFault, noFault, 3, 0)
+	actual, shared := runInProcess(t, descriptor, testSlaves, ga.Codecs[ga.DefaultCodec], testTimeout, injector.wrap)
+	assertSamePopulation(t, expected, actual)
+	injector.assertInjected(t)
+
+	if len(shared.DeadLetters()) == 0 {
+		t.Error("the corrupted tasks must be moved to the dead letters")
+	}
+}
+
+func TestMasterRecoversFromCorruptedResults(t *testing.T) {
+	descriptor := newTestDescriptor("ppeaks")
+	expected := runSequential(t, descriptor)
+	injector := newFaultInjector(noFault, corruptFault, 3, 0)
+	actual, shared := runInProcess(t, descriptor, testSlaves, ga.Codecs[ga.DefaultCodec], testTimeout, injector.wrap)
+	assertSamePopulation(t, expected, actual)
+	injector.assertInjected(t)
+
+	if len(shared.DeadLetters()) == 0 {
+		t.Error("the corrupted results must be moved to the dead letters")
+	}
+}
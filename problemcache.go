@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/pasqualesalza/amqpga/ga"
+)
+
+// A problem built from the context of an experiment.
+type cachedProblem struct {
+	hash    string
+	problem ga.Problem
+}
+
+// Keeps the problems of the experiments served by a slave, built from the
+// contexts published by their masters.
+type problemCache struct {
+	// Asks the master of an experiment for its context.
+	request func(experimentId string) error
+	// How long a request waits for its context, asking again every interval.
+	timeout       time.Duration
+	retryInterval time.Duration
+
+	mutex    sync.Mutex
+	problems map[string]*cachedProblem
+	contexts map[string]*ga.ProblemContext
+	// Closed and replaced when a context arrives.
+	arrived chan bool
+}
+
+func newProblemCache(request func(experimentId string) error, timeout time.Duration, retryInterval time.Duration) *problemCache {
+	return &problemCache{
+		request:       request,
+		timeout:       timeout,
+		retryInterval: retryInterval,
+		problems:      make(map[string]*cachedProblem),
+		contexts:      make(map[string]*ga.ProblemContext),
+		arrived:       make(chan bool),
+	}
+}
+
+// Stores an encoded context by its hash, waking up the requests waiting for it.
+func (cache *problemCache) add(data []byte) error {
+	context := new(ga.ProblemContext)
+	if err := context.Decode(data); err != nil {
+		return err
+	}
+	hash := ga.ProblemContextHash(data)
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if _, ok := cache.contexts[hash]; ok {
+		return nil
+	}
+	cache.contexts[hash] = context
+	close(cache.arrived)
+	cache.arrived = make(chan bool)
+
+	log.WithFields(log.Fields{
+		"experiment": context.ExperimentId,
+		"hash":       hash,
+	}).Debugf("Received the context of the experiment %v", context.ExperimentId)
+
+	return nil
+}
+
+// Returns the problem of an experiment with the context of the hash, waiting
+// for the context if it was not received yet.
+func (cache *problemCache) get(experimentId string, hash string) (ga.Problem, error) {
+	if experimentId == "" || hash == "" {
+		return nil, fmt.Errorf("missing experiment id or context hash")
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cached, ok := cache.problems[experimentId]; ok && cached.hash == hash {
+		return cached.problem, nil
+	}
+
+	var deadline <-chan time.Time
+	var retry *time.Ticker
+	requesting := true
+	for {
+		if context, ok := cache.contexts[hash]; ok {
+			if retry != nil {
+				retry.Stop()
+			}
+			if context.ExperimentId != experimentId {
+				return nil, fmt.Errorf("context %v belongs to the experiment %v instead of %v", hash, context.ExperimentId, experimentId)
+			}
+
+			// Builds under the lock, so that a problem is never built twice.
+			problem, err := context.NewProblem()
+			if err != nil {
+				return nil, err
+			}
+			cache.problems[experimentId] = &cachedProblem{hash: hash, problem: problem}
+
+			log.WithFields(log.Fields{
+				"experiment": experimentId,
+				"problem":    context.Descriptor.Name,
+				"hash":       hash,
+			}).Infof("Loaded the problem of the experiment %v", experimentId)
+
+			return problem, nil
+		}
+
+		arrived := cache.arrived
+		cache.mutex.Unlock()
+
+		if deadline == nil {
+			deadline = time.After(cache.timeout)
+			retry = time.NewTicker(cache.retryInterval)
+		}
+		// Asks again until the context arrives, in case the answer was lost.
+		if requesting {
+			if err := cache.request(experimentId); err != nil {
+				retry.Stop()
+				cache.mutex.Lock()
+				return nil, err
+			}
+			requesting = false
+		}
+
+		select {
+		case <-arrived:
+		case <-retry.C:
+			requesting = true
+		case <-deadline:
+			retry.Stop()
+			cache.mutex.Lock()
+			return nil, fmt.Errorf("context %v of the experiment %v not received", hash, experimentId)
+		}
+		cache.mutex.Lock()
+	}
+}
+
+// Forgets the problem and the contexts of a finished experiment.
+func (cache *problemCache) evict(experimentId string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for hash, context := range cache.contexts {
+		if context.ExperimentId == experimentId {
+			delete(cache.contexts, hash)
+		}
+	}
+	if _, ok := cache.problems[experimentId]; !ok {
+		return
+	}
+	delete(cache.problems, experimentId)
+
+	log.WithFields(log.Fields{
+		"experiment": experimentId,
+	}).Infof("Evicted the problem of the experiment %v", experimentId)
+}
+
+// Returns the number of cached problems.
+func (cache *problemCache) len() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return len(cache.problems)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pasqualesalza/amqpga/ga"
+)
+
+// Encodes the context of a P-Peaks problem with the peaks of the seed,
+// returning it with its hash.
+func encodeTestContext(t *testing.T, experimentId string, seed int64) ([]byte, string) {
+	descriptor := ga.ProblemDescriptor{Name: "ppeaks", Parameters: ga.ProblemParameters{ChromosomeSize: 8, PeaksNumber: 4, RandomSeed: seed}}
+	problem, err := descriptor.NewProblem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ga.NewProblemContext(experimentId, descriptor, problem).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data, ga.ProblemContextHash(data)
+}
+
+func TestProblemCacheRequestsTheContext(t *testing.T) {
+	data, hash := encodeTestContext(t, "a", 1)
+
+	var cache *problemCache
+	requests := 0
+	cache = newProblemCache(func(experimentId string) error {
+		requests++
+		go cache.add(data)
+		return nil
+	}, time.Second, time.Second)
+
+	first, err := cache.get("a", hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := cache.get("a", hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || requests != 1 {
+		t.Errorf("expected the cached problem after a single request, got %v requests", requests)
+	}
+
+	cache.evict("a")
+	if cache.len() != 0 {
+		t.Errorf("expected no problem after the eviction, got %v", cache.len())
+	}
+}
+
+func TestProblemCacheRefusesMismatchedContexts(t *testing.T) {
+	data, hash := encodeTestContext(t, "a", 1)
+	_, otherHash := encodeTestContext(t, "a", 2)
+
+	cache := newProblemCache(func(experimentId string) error {
+		return nil
+	}, 50*time.Millisecond, 10*time.Millisecond)
+	if err := cache.add(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cache.get("a", hash); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.get("a", otherHash); err == nil {
+		t.Error("expected an error for a context never received")
+	}
+	if _, err := cache.get("b", hash); err == nil {
+		t.Error("expected an error for the context of another experiment")
+	}
+	if _, err := cache.get("", hash); err == nil {
+		t.Error("expected an error without experiment id")
+	}
+	if err := cache.add([]byte("malformed")); err == nil {
+		t.Error("expected an error for a malformed context")
+	}
+}
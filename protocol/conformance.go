@@ -0,0 +1,181 @@
+package protocol
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/pasqualesalza/amqpga/ga"
+	"github.com/pasqualesalza/amqpga/transport"
+)
+
+// Evaluates a task message, returning the result message. A refused task is an
+// error.
+type Worker func(task *transport.Message) (*transport.Message, error)
+
+// Checks that a worker follows the protocol, sending it tasks of a problem and
+// comparing its results with the fitness values of the problem.
+type Conformance struct {
+	Problem      ga.Problem
+	ExperimentId string
+	ContextHash  string
+	// The queue the worker replies to.
+	ReplyTo string
+	// The names of the codecs supported by the worker.
+	Codecs []string
+	// The relative tolerance of the float fitness values, computed by other
+	// languages.
+	Tolerance float64
+	// Derives the chromosomes of the tasks.
+	Seed int64
+}
+
+// The batch sizes of the tasks sent for every codec.
+var conformanceBatchSizes = []int{1, 3}
+
+// Runs the suite against the worker, returning the violations of the
+// protocol.
+func (conformance *Conformance) Run(worker Worker) []error {
+	failures := make([]error, 0)
+	fail := func(format string, arguments ...interface{}) {
+		failures = append(failures, fmt.Errorf(format, arguments...))
+	}
+
+	id := int64(0)
+	for _, name := range conformance.Codecs {
+		codec, ok := ga.Codecs[name]
+		if !ok {
+			fail("unknown codec %v", name)
+			continue
+		}
+
+		for generation, batchSize := range conformanceBatchSizes {
+			individuals := make([]*ga.Individual, batchSize)
+			for i := range individuals {
+				individuals[i] = &ga.Individual{
+					Id:         id,
+					Generation: int64(generation),
+					Chromosome: conformance.Problem.NewChromosome(ga.NewRandom(conformance.Seed, ga.InitializationStream, id)),
+				}
+				id++
+			}
+
+			task := &Task{
+				ExperimentId: conformance.ExperimentId,
+				ContextHash:  conformance.ContextHash,
+				Individuals:  individuals,
+				Codec:        codec,
+				ReplyTo:      conformance.ReplyTo,
+			}
+			message, err := task.Encode()
+			if err != nil {
+				// The codec does not support the chromosomes of the problem.
+				fail("%v: %v", name, err)
+				break
+			}
+
+			result, err := worker(message)
+			if err != nil {
+				fail("%v: batch of %v refused: %v", name, batchSize, err)
+				continue
+			}
+			for _, err := range conformance.check(task, message.CorrelationId, result) {
+				fail("%v: batch of %v: %v", name, batchSize, err)
+			}
+		}
+	}
+
+	// A task with an unknown content type has to be refused.
+	task := &Task{
+		ExperimentId: conformance.ExperimentId,
+		ContextHash:  conformance.ContextHash,
+		Individuals:  []*ga.Individual{{Id: id, Chromosome: conformance.Problem.NewChromosome(ga.NewRandom(conformance.Seed, ga.InitializationStream, id))}},
+		Codec:        ga.Codecs[ga.DefaultCodec],
+		ReplyTo:      conformance.ReplyTo,
+	}
+	message, err := task.Encode()
+	if err != nil {
+		fail("%v", err)
+		return failures
+	}
+	message.ContentType = "application/x-unknown"
+	if _, err := worker(message); err == nil {
+		fail("task with an unknown content type accepted")
+	}
+
+	return failures
+}
+
+// Checks the result of a task.
+func (conformance *Conformance) check(task *Task, correlationId string, message *transport.Message) []error {
+	if message.CorrelationId != correlationId {
+		return []error{fmt.Errorf("correlation id %q instead of %q", message.CorrelationId, correlationId)}
+	}
+	if message.ContentType != task.Codec.ContentType() || message.ContentEncoding != task.Codec.ContentEncoding() {
+		return []error{fmt.Errorf("content type %q with encoding %q instead of the ones of the task", message.ContentType, message.ContentEncoding)}
+	}
+	result, err := DecodeResult(message)
+	if err != nil {
+		return []error{err}
+	}
+	if result.ExperimentId != task.ExperimentId {
+		return []error{fmt.Errorf("experiment %q instead of %q", result.ExperimentId, task.ExperimentId)}
+	}
+	if len(result.Individuals) != len(task.Individuals) {
+		return []error{fmt.Errorf("%v individuals instead of %v", len(result.Individuals), len(task.Individuals))}
+	}
+
+	failures := make([]error, 0)
+	for i, individual := range result.Individuals {
+		expected := task.Individuals[i]
+		if individual.Id != expected.Id || individual.Generation != expected.Generation {
+			failures = append(failures, fmt.Errorf("individual %v of generation %v instead of %v of generation %v", individual.Id, individual.Generation, expected.Id, expected.Generation))
+			continue
+		}
+		if !reflect.DeepEqual(individual.Chromosome, expected.Chromosome) {
+			failures = append(failures, fmt.Errorf("chromosome of the individual %v changed", individual.Id))
+			continue
+		}
+		fitnessValue := conformance.Problem.Evaluate(expected)
+		if !conformance.equal(individual.FitnessValue, fitnessValue) {
+			failures = append(failures, fmt.Errorf("fitness value %v of the individual %v instead of %v", individual.FitnessValue, individual.Id, fitnessValue))
+		}
+	}
+	return failures
+}
+
+// Compares two fitness values, the float ones within the tolerance.
+func (conformance *Conformance) equal(actual, expected ga.FitnessValue) bool {
+	var actualFloat, expectedFloat float64
+	switch expectedValue := expected.(type) {
+	case ga.Float32FitnessValue:
+		actualValue, ok := actual.(ga.Float32FitnessValue)
+		if !ok {
+			return false
+		}
+		actualFloat, expectedFloat = float64(actualValue), float64(expectedValue)
+	case ga.Float64FitnessValue:
+		actualValue, ok := actual.(ga.Float64FitnessValue)
+		if !ok {
+			return false
+		}
+		actualFloat, expectedFloat = float64(actualValue), float64(expectedValue)
+	default:
+		return actual == expected
+	}
+	return math.Abs(actualFloat-expectedFloat) <= conformance.Tolerance*math.Max(1, math.Abs(expectedFloat))
+}
+
+// Returns a worker evaluating the tasks with a problem, as the Go slaves do.
+func NewWorker(problem ga.Problem) Worker {
+	return func(message *transport.Message) (*transport.Message, error) {
+		task, err := DecodeTask(message)
+		if err != nil {
+			return nil, err
+		}
+		for _, individual := range task.Individuals {
+			individual.FitnessValue = problem.Evaluate(individual)
+		}
+		return NewResult(task, 0).Encode()
+	}
+}
@@ -0,0 +1,107 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/pasqualesalza/amqpga/ga"
+	"github.com/pasqualesalza/amqpga/transport"
+)
+
+func testConformance(codecs ...string) *Conformance {
+	problem, _ := ga.NewProblem("sphere", ga.ProblemParameters{ChromosomeSize: 5})
+	return &Conformance{
+		Problem:      problem,
+		ExperimentId: "abc",
+		ContextHash:  "hash",
+		ReplyTo:      "reply",
+		Codecs:       codecs,
+		Tolerance:    1e-9,
+	}
+}
+
+// Evaluates the sphere function knowing only the JSON schema and the headers,
+// as a worker written in another language does.
+func fakeWorker(task *transport.Message) (*transport.Message, error) {
+	if task.ContentType != "application/json" {
+		return nil, fmt.Errorf("unsupported content type %v", task.ContentType)
+	}
+
+	var individuals []map[string]interface{}
+	if err := json.Unmarshal(task.Body, &individuals); err != nil {
+		return nil, err
+	}
+	for _, individual := range individuals {
+		sum := 0.0
+		for _, gene := range individual["genes"].([]interface{}) {
+			sum += gene.(float64) * gene.(float64)
+		}
+		individual["fitnessType"] = "float64"
+		individual["fitnessValue"] = sum
+	}
+	body, err := json.Marshal(individuals)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]interface{}{"evaluationTime": int64(0)}
+	for _, name := range []string{"experimentId", "individualId", "generation", "chromosomeType", "batchSize"} {
+		headers[name] = task.Headers[name]
+	}
+	return &transport.Message{
+		ContentType:   task.ContentType,
+		CorrelationId: task.CorrelationId,
+		Headers:       headers,
+		Body:          body,
+	}, nil
+}
+
+func TestConformanceGoWorker(t *testing.T) {
+	conformance := testConformance(ga.CodecNames()...)
+	for _, err := range conformance.Run(NewWorker(conformance.Problem)) {
+		t.Error(err)
+	}
+}
+
+func TestConformanceFakeWorker(t *testing.T) {
+	for _, err := range testConformance("json").Run(fakeWorker) {
+		t.Error(err)
+	}
+}
+
+func TestConformanceViolations(t *testing.T) {
+	for name, worker := range map[string]Worker{
+		"wrong fitness": func(task *transport.Message) (*transport.Message, error) {
+			message, err := fakeWorker(task)
+			if err == nil {
+				message.Body = []byte(`[{"id": 0, "chromosomeType": "float64", "genes": [0, 0, 0, 0, 0], "fitnessType": "float64", "fitnessValue": 1}]`)
+			}
+			return message, err
+		},
+		"missing header": func(task *transport.Message) (*transport.Message, error) {
+			message, err := fakeWorker(task)
+			if err == nil {
+				delete(message.Headers, "batchSize")
+			}
+			return message, err
+		},
+		"wrong correlation id": func(task *transport.Message) (*transport.Message, error) {
+			message, err := fakeWorker(task)
+			if err == nil {
+				message.CorrelationId = "x"
+			}
+			return message, err
+		},
+		"accepts anything": func(task *transport.Message) (*transport.Message, error) {
+			if task.ContentType != "application/json" {
+				return &transport.Message{}, nil
+			}
+			return fakeWorker(task)
+		},
+	} {
+		if failures := testConformance("json").Run(worker); len(failures) == 0 {
+			t.Errorf("%v: expected protocol violations", name)
+		}
+	}
+}
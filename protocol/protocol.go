@@ -0,0 +1,238 @@
+// Implements the language-neutral messages exchanged by the masters and the
+// slaves, described in PROTOCOL.md.
+package protocol
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pasqualesalza/amqpga/config"
+	"github.com/pasqualesalza/amqpga/ga"
+	"github.com/pasqualesalza/amqpga/transport"
+)
+
+// A batch of individuals to evaluate, sent by a master to the slaves.
+type Task struct {
+	ExperimentId string
+	// The hash of the problem context, for the slaves decoding it.
+	ContextHash string
+	Individuals []*ga.Individual
+	Codec       ga.Codec
+	// The queue of the result.
+	ReplyTo string
+}
+
+// A batch of individuals evaluated by a slave, sent back to its master.
+type Result struct {
+	ExperimentId   string
+	Individuals    []*ga.Individual
+	Codec          ga.Codec
+	EvaluationTime time.Duration
+}
+
+// Returns the correlation id of a batch, the id of its first individual.
+func CorrelationId(individuals []*ga.Individual) string {
+	return strconv.FormatInt(individuals[0].Id, 10)
+}
+
+// Returns the headers describing a batch.
+func batchHeaders(experimentId string, individuals []*ga.Individual) (map[string]interface{}, error) {
+	if len(individuals) == 0 {
+		return nil, fmt.Errorf("empty batch")
+	}
+	chromosomeType, err := ga.ChromosomeType(individuals[0].Chromosome)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		config.ExperimentIdHeader:   experimentId,
+		config.IndividualIdHeader:   individuals[0].Id,
+		config.GenerationHeader:     individuals[0].Generation,
+		config.ChromosomeTypeHeader: chromosomeType,
+		config.BatchSizeHeader:      int64(len(individuals)),
+	}, nil
+}
+
+// Encodes the task in a message.
+func (task *Task) Encode() (*transport.Message, error) {
+	headers, err := batchHeaders(task.ExperimentId, task.Individuals)
+	if err != nil {
+		return nil, err
+	}
+	headers[config.ContextHashHeader] = task.ContextHash
+
+	data, err := task.Codec.Encode(task.Individuals)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transport.Message{
+		ContentType:     task.Codec.ContentType(),
+		ContentEncoding: task.Codec.ContentEncoding(),
+		CorrelationId:   CorrelationId(task.Individuals),
+		ReplyTo:         task.ReplyTo,
+		Headers:         headers,
+		Body:            data,
+	}, nil
+}
+
+// Decodes the task of a message, checking it against its headers.
+func DecodeTask(message *transport.Message) (*Task, error) {
+	if message.ReplyTo == "" {
+		return nil, fmt.Errorf("missing reply queue")
+	}
+	contextHash, _ := message.Headers[config.ContextHashHeader].(string)
+
+	experimentId, individuals, codec, err := decodeBatch(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Task{
+		ExperimentId: experimentId,
+		ContextHash:  contextHash,
+		Individuals:  individuals,
+		Codec:        codec,
+		ReplyTo:      message.ReplyTo,
+	}, nil
+}
+
+// Returns the result of a task, evaluated in the evaluation time.
+func NewResult(task *Task, evaluationTime time.Duration) *Result {
+	return &Result{
+		ExperimentId:   task.ExperimentId,
+		Individuals:    task.Individuals,
+		Codec:          task.Codec,
+		EvaluationTime: evaluationTime,
+	}
+}
+
+// Encodes the result in a message.
+func (result *Result) Encode() (*transport.Message, error) {
+	headers, err := batchHeaders(result.ExperimentId, result.Individuals)
+	if err != nil {
+		return nil, err
+	}
+	headers[config.EvaluationTimeHeader] = int64(result.EvaluationTime)
+
+	data, err := result.Codec.Encode(result.Individuals)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transport.Message{
+		ContentType:     result.Codec.ContentType(),
+		ContentEncoding: result.Codec.ContentEncoding(),
+		CorrelationId:   CorrelationId(result.Individuals),
+		Headers:         headers,
+		Body:            data,
+	}, nil
+}
+
+// Decodes the result of a message, checking it against its headers and that
+// every individual is evaluated.
+func DecodeResult(message *transport.Message) (*Result, error) {
+	experimentId, individuals, codec, err := decodeBatch(message)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, individual := range individuals {
+		if individual.FitnessValue == nil {
+			return nil, fmt.Errorf("individual %v not evaluated", individual.Id)
+		}
+	}
+	evaluationTime, err := intHeader(message.Headers, config.EvaluationTimeHeader)
+	if err != nil {
+		return nil, err
+	}
+	if evaluationTime < 0 {
+		return nil, fmt.Errorf("negative evaluation time %v", evaluationTime)
+	}
+
+	return &Result{
+		ExperimentId:   experimentId,
+		Individuals:    individuals,
+		Codec:          codec,
+		EvaluationTime: time.Duration(evaluationTime),
+	}, nil
+}
+
+// Decodes the batch of a message with the codec of its content type, checking
+// that the batch matches the headers.
+func decodeBatch(message *transport.Message) (string, []*ga.Individual, ga.Codec, error) {
+	experimentId, _ := message.Headers[config.ExperimentIdHeader].(string)
+	if experimentId == "" {
+		return "", nil, nil, fmt.Errorf("missing %v header", config.ExperimentIdHeader)
+	}
+
+	codec, err := ga.CodecFor(message.ContentType, message.ContentEncoding)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	individuals, err := codec.Decode(message.Body)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if len(individuals) == 0 {
+		return "", nil, nil, fmt.Errorf("empty batch")
+	}
+
+	if message.CorrelationId != CorrelationId(individuals) {
+		return "", nil, nil, fmt.Errorf("correlation id %q does not match the first individual %v", message.CorrelationId, individuals[0].Id)
+	}
+	individualId, err := intHeader(message.Headers, config.IndividualIdHeader)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if individualId != individuals[0].Id {
+		return "", nil, nil, fmt.Errorf("%v header %v does not match the first individual %v", config.IndividualIdHeader, individualId, individuals[0].Id)
+	}
+	generation, err := intHeader(message.Headers, config.GenerationHeader)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if generation != individuals[0].Generation {
+		return "", nil, nil, fmt.Errorf("%v header %v does not match the first individual %v", config.GenerationHeader, generation, individuals[0].Generation)
+	}
+	batchSize, err := intHeader(message.Headers, config.BatchSizeHeader)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if batchSize != int64(len(individuals)) {
+		return "", nil, nil, fmt.Errorf("%v header %v does not match the %v individuals", config.BatchSizeHeader, batchSize, len(individuals))
+	}
+	chromosomeType, _ := message.Headers[config.ChromosomeTypeHeader].(string)
+	for _, individual := range individuals {
+		if actual, err := ga.ChromosomeType(individual.Chromosome); err != nil || actual != chromosomeType {
+			return "", nil, nil, fmt.Errorf("%v header %q does not match the chromosome of the individual %v", config.ChromosomeTypeHeader, chromosomeType, individual.Id)
+		}
+	}
+
+	return experimentId, individuals, codec, nil
+}
+
+// Returns an integer header, whatever the size the publisher chose for it.
+func intHeader(headers map[string]interface{}, name string) (int64, error) {
+	switch value := headers[name].(type) {
+	case int8:
+		return int64(value), nil
+	case uint8:
+		return int64(value), nil
+	case int16:
+		return int64(value), nil
+	case uint16:
+		return int64(value), nil
+	case int32:
+		return int64(value), nil
+	case uint32:
+		return int64(value), nil
+	case int64:
+		return value, nil
+	case nil:
+		return 0, fmt.Errorf("missing %v header", name)
+	default:
+		return 0, fmt.Errorf("%v header of type %T is not an integer", name, value)
+	}
+}
@@ -0,0 +1,107 @@
+package protocol
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pasqualesalza/amqpga/config"
+	"github.com/pasqualesalza/amqpga/ga"
+	"github.com/pasqualesalza/amqpga/transport"
+)
+
+func testTask() *Task {
+	return &Task{
+		ExperimentId: "abc",
+		ContextHash:  "hash",
+		Individuals: []*ga.Individual{
+			{Id: 4, Generation: 2, Chromosome: ga.Float64VectorChromosome{1, 2}},
+			{Id: 5, Generation: 2, Chromosome: ga.Float64VectorChromosome{3, 4}},
+		},
+		Codec:   ga.Codecs["json"],
+		ReplyTo: "reply",
+	}
+}
+
+func TestTaskRoundTrip(t *testing.T) {
+	task := testTask()
+	message, err := task.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.CorrelationId != "4" || message.ContentType != ga.JSONContentType {
+		t.Errorf("unexpected message %v", message)
+	}
+
+	decoded, err := DecodeTask(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, task) {
+		t.Errorf("expected %v, got %v", task, decoded)
+	}
+}
+
+func TestResultRoundTrip(t *testing.T) {
+	task := testTask()
+	for _, individual := range task.Individuals {
+		individual.FitnessValue = ga.Float64FitnessValue(1)
+	}
+	result := NewResult(task, time.Second)
+	message, err := result.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.ReplyTo != "" {
+		t.Errorf("unexpected reply queue %v", message.ReplyTo)
+	}
+
+	decoded, err := DecodeResult(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, result) {
+		t.Errorf("expected %v, got %v", result, decoded)
+	}
+}
+
+func TestDecodeResultNotEvaluated(t *testing.T) {
+	message, _ := NewResult(testTask(), time.Second).Encode()
+	if _, err := DecodeResult(message); err == nil {
+		t.Error("a result with individuals not evaluated has to be refused")
+	}
+}
+
+func TestDecodeTaskInvalidHeaders(t *testing.T) {
+	for name, change := range map[string]func(message *transport.Message){
+		"reply queue":    func(message *transport.Message) { message.ReplyTo = "" },
+		"correlation id": func(message *transport.Message) { message.CorrelationId = "5" },
+		"content type":   func(message *transport.Message) { message.ContentType = "text/plain" },
+		"experiment id":  func(message *transport.Message) { delete(message.Headers, config.ExperimentIdHeader) },
+		"individual id":  func(message *transport.Message) { message.Headers[config.IndividualIdHeader] = int64(5) },
+		"generation":     func(message *transport.Message) { message.Headers[config.GenerationHeader] = "2" },
+		"batch size":     func(message *transport.Message) { message.Headers[config.BatchSizeHeader] = int32(1) },
+		"chromosome":     func(message *transport.Message) { message.Headers[config.ChromosomeTypeHeader] = ga.IntType },
+		"empty batch":    func(message *transport.Message) { message.Body = []byte("[]") },
+	} {
+		message, _ := testTask().Encode()
+		change(message)
+		if _, err := DecodeTask(message); err == nil {
+			t.Errorf("%v: an invalid task has to be refused", name)
+		}
+	}
+}
+
+func TestIntHeader(t *testing.T) {
+	// The publishers written in other languages may choose smaller integers.
+	for _, value := range []interface{}{int8(7), uint8(7), int16(7), uint16(7), int32(7), uint32(7), int64(7)} {
+		if actual, err := intHeader(map[string]interface{}{"value": value}, "value"); err != nil || actual != 7 {
+			t.Errorf("%T: unexpected %v, %v", value, actual, err)
+		}
+	}
+	for _, value := range []interface{}{nil, "7", 7.0} {
+		if _, err := intHeader(map[string]interface{}{"value": value}, "value"); err == nil {
+			t.Errorf("%T: expected an error", value)
+		}
+	}
+}
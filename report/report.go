@@ -1,23 +1,28 @@
 package report
 
 import (
+	"fmt"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
-
-	"github.com/pasqualesalza/amqpga/util"
 )
 
-func Connect(host string) *mgo.Session {
+func Connect(host string) (*mgo.Session, error) {
 	mongoSession, err := mgo.Dial(host)
-	util.FailOnError(err, "Failed to connect to MongoDB.")
-	return mongoSession
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %v", err)
+	}
+	return mongoSession, nil
 }
 
 func MillisecondsSince(t time.Time) int64 {
-	return time.Since(t).Nanoseconds() / (int64(time.Millisecond) / int64(time.Nanosecond))
+	return Milliseconds(time.Since(t))
+}
+
+func Milliseconds(d time.Duration) int64 {
+	return d.Nanoseconds() / (int64(time.Millisecond) / int64(time.Nanosecond))
 }
 
 type Experiment struct {
@@ -35,6 +40,33 @@ type Experiment struct {
 	MutationRate            float64       "mutationRate"
 	PeaksNumber             int64         "peaksNumber"
 	SleepTime               int64         "sleepTime"
+	InstanceName            string        "instanceName"
+	CrossoverOperator       string        "crossoverOperator"
+	MutationOperator        string        "mutationOperator"
+	ScheduleBuilder         string        "scheduleBuilder"
+	InstanceFile            string        "instanceFile"
+	ElitesNumber            int           "elitesNumber"
+	Mode                    string        "mode"
+	EvaluationsNumber       int64         "evaluationsNumber"
+	InFlightNumber          int           "inFlightNumber"
+	IslandsNumber           int           "islandsNumber"
+	Topology                string        "topology"
+	MigrationInterval       int64         "migrationInterval"
+	MigrantsNumber          int           "migrantsNumber"
+	MigrantSelection        string        "migrantSelection"
+	MigrantReplacement      string        "migrantReplacement"
+	IslandIndex             int           "islandIndex"
+	BatchSize               int           "batchSize"
+	AdaptiveBatch           bool          "adaptiveBatch"
+	Timeout                 int64         "timeout"
+	Retries                 int           "retries"
+	TimeoutPolicy           string        "timeoutPolicy"
+	Codec                   string        "codec"
+	WorkersNumber           int           "workersNumber"
+	ShiftFile               string        "shiftFile"
+	RotationFile            string        "rotationFile"
+	BlockSize               int           "blockSize"
+	Epistasis               int           "epistasis"
 }
 
 type Time struct {
@@ -64,9 +96,11 @@ type Latency struct {
 	Time               int64         "time"
 }
 
-func ReportExperiment(experiment *Experiment, collection *mgo.Collection) bson.ObjectId {
+func ReportExperiment(experiment *Experiment, collection *mgo.Collection) (bson.ObjectId, error) {
 	experiment.Id = bson.NewObjectId()
-	collection.Insert(experiment)
+	if err := collection.Insert(experiment); err != nil {
+		return "", fmt.Errorf("failed to register the experiment: %v", err)
+	}
 	log.WithFields(log.Fields{
 		"id":                      experiment.Id,
 		"randomId":                experiment.RandomId,
@@ -81,14 +115,43 @@ func ReportExperiment(experiment *Experiment, collection *mgo.Collection) bson.O
 		"crossoverRate":           experiment.CrossoverRate,
 		"mutationRate":            experiment.MutationRate,
 		"peaksNumber":             experiment.PeaksNumber,
+		"instanceName":            experiment.InstanceName,
+		"crossoverOperator":       experiment.CrossoverOperator,
+		"mutationOperator":        experiment.MutationOperator,
+		"scheduleBuilder":         experiment.ScheduleBuilder,
+		"instanceFile":            experiment.InstanceFile,
+		"elitesNumber":            experiment.ElitesNumber,
+		"mode":                    experiment.Mode,
+		"evaluationsNumber":       experiment.EvaluationsNumber,
+		"inFlightNumber":          experiment.InFlightNumber,
+		"islandsNumber":           experiment.IslandsNumber,
+		"topology":                experiment.Topology,
+		"migrationInterval":       experiment.MigrationInterval,
+		"migrantsNumber":          experiment.MigrantsNumber,
+		"migrantSelection":        experiment.MigrantSelection,
+		"migrantReplacement":      experiment.MigrantReplacement,
+		"islandIndex":             experiment.IslandIndex,
+		"batchSize":               experiment.BatchSize,
+		"adaptiveBatch":           experiment.AdaptiveBatch,
+		"timeout":                 experiment.Timeout,
+		"retries":                 experiment.Retries,
+		"timeoutPolicy":           experiment.TimeoutPolicy,
+		"codec":                   experiment.Codec,
+		"workersNumber":           experiment.WorkersNumber,
+		"shiftFile":               experiment.ShiftFile,
+		"rotationFile":            experiment.RotationFile,
+		"blockSize":               experiment.BlockSize,
+		"epistasis":               experiment.Epistasis,
 	}).Info("Experiment registered")
-	return experiment.Id
+	return experiment.Id, nil
 }
 
-func ReportTime(time *Time, collection *mgo.Collection) {
+func ReportTime(time *Time, collection *mgo.Collection) error {
 	if collection != nil {
 		time.Id = bson.NewObjectId()
-		collection.Insert(time)
+		if err := collection.Insert(time); err != nil {
+			return fmt.Errorf("failed to register the time: %v", err)
+		}
 		log.WithFields(log.Fields{
 			"id":         time.Id,
 			"experiment": time.Experiment,
@@ -97,12 +160,15 @@ func ReportTime(time *Time, collection *mgo.Collection) {
 			"time":       time.Time,
 		}).Info("Time registered")
 	}
+	return nil
 }
 
-func ReportIndividual(individual *Individual, collection *mgo.Collection) {
+func ReportIndividual(individual *Individual, collection *mgo.Collection) error {
 	if collection != nil {
 		individual.Id = bson.NewObjectId()
-		collection.Insert(individual)
+		if err := collection.Insert(individual); err != nil {
+			return fmt.Errorf("failed to register the individual: %v", err)
+		}
 		log.WithFields(log.Fields{
 			"id":           individual.Id,
 			"experiment":   individual.Experiment,
@@ -112,12 +178,15 @@ func ReportIndividual(individual *Individual, collection *mgo.Collection) {
 			"fitnessValue": individual.FitnessValue,
 		}).Info("Individual registered")
 	}
+	return nil
 }
 
-func ReportLatency(latency *Latency, collection *mgo.Collection) {
+func ReportLatency(latency *Latency, collection *mgo.Collection) error {
 	if collection != nil {
 		latency.Id = bson.NewObjectId()
-		collection.Insert(latency)
+		if err := collection.Insert(latency); err != nil {
+			return fmt.Errorf("failed to register the latency: %v", err)
+		}
 		log.WithFields(log.Fields{
 			"id":                 latency.Id,
 			"nodeId":             latency.NodeId,
@@ -128,4 +197,5 @@ func ReportLatency(latency *Latency, collection *mgo.Collection) {
 			"time":               latency.Time,
 		}).Info("Latency registered")
 	}
+	return nil
 }
@@ -0,0 +1,24 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReportWithoutCollection(t *testing.T) {
+	if err := ReportTime(&Time{Type: "generation"}, nil); err != nil {
+		t.Error(err)
+	}
+	if err := ReportIndividual(&Individual{Type: "bestIndividual"}, nil); err != nil {
+		t.Error(err)
+	}
+	if err := ReportLatency(&Latency{Type: "start"}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMilliseconds(t *testing.T) {
+	if milliseconds := Milliseconds(1500 * time.Microsecond); milliseconds != 1 {
+		t.Errorf("expected 1, got %v", milliseconds)
+	}
+}
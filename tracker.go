@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pasqualesalza/amqpga/ga"
+)
+
+// Policies applied to an individual that times out after its retries.
+const (
+	failTimeoutPolicy  = "fail"
+	localTimeoutPolicy = "local"
+	worstTimeoutPolicy = "worst"
+)
+
+var timeoutPolicies = []string{failTimeoutPolicy, localTimeoutPolicy, worstTimeoutPolicy}
+
+// An individual published to the slaves and waiting for its response.
+type pendingIndividual struct {
+	individual *ga.Individual
+	deadline   time.Time
+	attempts   int
+}
+
+// Tracks the individuals published to the slaves by id, so that the late and
+// duplicate responses are discarded and the lost individuals are published
+// again when their deadline passes.
+type evaluationTracker struct {
+	timeout      time.Duration
+	retries      int
+	policy       string
+	problem      ga.Problem
+	minimization bool
+	// An individual of the problem, that the received individuals must fit.
+	reference *ga.Individual
+
+	mutex   sync.Mutex
+	pending map[int64]*pendingIndividual
+	// The worst fitness value received, assigned by the worst policy.
+	worst ga.FitnessValue
+}
+
+// Creates a tracker. A zero timeout never expires the individuals.
+func newEvaluationTracker(timeout time.Duration, retries int, policy string, problem ga.Problem) (*evaluationTracker, error) {
+	switch policy {
+	case failTimeoutPolicy, localTimeoutPolicy, worstTimeoutPolicy:
+	default:
+		return nil, fmt.Errorf("unknown timeout policy %v", policy)
+	}
+	if timeout < 0 || retries < 0 {
+		return nil, fmt.Errorf("invalid timeout %v with %v retries", timeout, retries)
+	}
+
+	return &evaluationTracker{
+		timeout:      timeout,
+		retries:      retries,
+		policy:       policy,
+		problem:      problem,
+		minimization: problem.Minimization(),
+		reference:    referenceIndividual(problem),
+		pending:      make(map[int64]*pendingIndividual),
+	}, nil
+}
+
+// Starts the deadline of published individuals.
+func (tracker *evaluationTracker) track(individuals []*ga.Individual, now time.Time) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	for _, individual := range individuals {
+		pending, ok := tracker.pending[individual.Id]
+		if !ok {
+			pending = &pendingIndividual{individual: individual}
+			tracker.pending[individual.Id] = pending
+		}
+		pending.deadline = now.Add(tracker.timeout)
+	}
+}
+
+// Returns an evaluated individual of the problem.
+func referenceIndividual(problem ga.Problem) *ga.Individual {
+	individual := &ga.Individual{Chromosome: problem.NewChromosome(ga.NewRandom(0, ga.InitializationStream))}
+	individual.FitnessValue = problem.Evaluate(individual)
+	return individual
+}
+
+// Checks that the received individuals fit the problem, so that a slave
+// returning the wrong types cannot break the engine.
+func (tracker *evaluationTracker) check(individuals []*ga.Individual) error {
+	for _, individual := range individuals {
+		if err := individual.Fits(tracker.reference); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stops tracking a received individual, telling if it was pending.
+func (tracker *evaluationTracker) receive(individual *ga.Individual) bool {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	if _, ok := tracker.pending[individual.Id]; !ok {
+		return false
+	}
+	delete(tracker.pending, individual.Id)
+
+	if tracker.worst == nil || tracker.worse(individual.FitnessValue, tracker.worst) {
+		tracker.worst = individual.FitnessValue
+	}
+	return true
+}
+
+func (tracker *evaluationTracker) worse(fitnessValue, other ga.FitnessValue) bool {
+	if tracker.minimization {
+		return other.Less(fitnessValue)
+	}
+	return fitnessValue.Less(other)
+}
+
+// Returns the number of pending individuals.
+func (tracker *evaluationTracker) len() int {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	return len(tracker.pending)
+}
+
+// Returns the pending individuals sorted by id.
+func (tracker *evaluationTracker) individuals() []*ga.Individual {
+	tracker.mutex.Lock()
+	individuals := make(ga.SortByIdIndividuals, 0, len(tracker.pending))
+	for _, pending := range tracker.pending {
+		individuals = append(individuals, pending.individual)
+	}
+	tracker.mutex.Unlock()
+
+	sort.Sort(individuals)
+	return individuals
+}
+
+// Returns a channel ticking twice per timeout to check the deadlines, or nil
+// if the individuals never expire.
+func (tracker *evaluationTracker) ticker() (<-chan time.Time, func()) {
+	if tracker.timeout == 0 {
+		return nil, func() {}
+	}
+	ticker := time.NewTicker(tracker.timeout / 2)
+	return ticker.C, ticker.Stop
+}
+
+// Handles the individuals whose deadline passed, returning the ones to be
+// published again and the ones evaluated by the timeout policy once their
+// retries are over.
+func (tracker *evaluationTracker) expire(now time.Time) (resubmitted []*ga.Individual, evaluated []*ga.Individual, err error) {
+	tracker.mutex.Lock()
+	expired := make(ga.SortByIdIndividuals, 0)
+	for _, pending := range tracker.pending {
+		if tracker.timeout > 0 && !now.Before(pending.deadline) {
+			expired = append(expired, pending.individual)
+		}
+	}
+	sort.Sort(expired)
+
+	for _, individual := range expired {
+		pending := tracker.pending[individual.Id]
+		if pending.attempts < tracker.retries {
+			pending.attempts++
+			pending.deadline = now.Add(tracker.timeout)
+			resubmitted = append(resubmitted, individual)
+			continue
+		}
+
+		switch tracker.policy {
+		case failTimeoutPolicy:
+			tracker.mutex.Unlock()
+			return nil, nil, fmt.Errorf("individual %v timed out after %v retries", individual.Id, tracker.retries)
+		case worstTimeoutPolicy:
+			if tracker.worst == nil {
+				tracker.mutex.Unlock()
+				return nil, nil, fmt.Errorf("individual %v timed out before any fitness value was received", individual.Id)
+			}
+			individual.FitnessValue = tracker.worst
+		}
+		delete(tracker.pending, individual.Id)
+		evaluated = append(evaluated, individual)
+	}
+	tracker.mutex.Unlock()
+
+	// Evaluates outside the lock, since it may take long.
+	if tracker.policy == localTimeoutPolicy {
+		for _, individual := range evaluated {
+			individual.FitnessValue = tracker.problem.Evaluate(individual)
+		}
+	}
+
+	return resubmitted, evaluated, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pasqualesalza/amqpga/ga"
+)
+
+func newTestTracker(t *testing.T, retries int, policy string) (*evaluationTracker, []*ga.Individual) {
+	problem, err := ga.NewProblem("sphere", ga.ProblemParameters{ChromosomeSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := newEvaluationTracker(time.Second, retries, policy, problem)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	individuals := make([]*ga.Individual, 3)
+	for i := range individuals {
+		individuals[i] = &ga.Individual{Id: int64(i), Chromosome: problem.NewChromosome(ga.NewRandom(0, ga.InitializationStream, int64(i)))}
+	}
+	return tracker, individuals
+}
+
+func TestEvaluationTrackerDiscardsDuplicates(t *testing.T) {
+	tracker, individuals := newTestTracker(t, 0, failTimeoutPolicy)
+	tracker.track(individuals, time.Now())
+
+	individuals[0].FitnessValue = ga.Float64FitnessValue(1)
+	if !tracker.receive(individuals[0]) {
+		t.Error("the first response must be received")
+	}
+	if tracker.receive(individuals[0]) {
+		t.Error("the duplicate response must be discarded")
+	}
+	if pending := tracker.len(); pending != 2 {
+		t.Errorf("expected 2 pending individuals, got %v", pending)
+	}
+}
+
+func TestEvaluationTrackerChecksTypes(t *testing.T) {
+	tracker, individuals := newTestTracker(t, 0, failTimeoutPolicy)
+
+	individuals[0].FitnessValue = ga.Float64FitnessValue(1)
+	if err := tracker.check(individuals[:1]); err != nil {
+		t.Error(err)
+	}
+
+	// A fitness value or a chromosome of another problem is refused.
+	individuals[1].FitnessValue = ga.IntFitnessValue(1)
+	if err := tracker.check(individuals[:2]); err == nil {
+		t.Error("expected an error for an int fitness value")
+	}
+	individuals[2].Chromosome = ga.Float64VectorChromosome{0}
+	if err := tracker.check(individuals[2:]); err == nil {
+		t.Error("expected an error for a shorter chromosome")
+	}
+}
+
+func TestEvaluationTrackerRetries(t *testing.T) {
+	tracker, individuals := newTestTracker(t, 1, failTimeoutPolicy)
+	now := time.Now()
+	tracker.track(individuals, now)
+
+	resubmitted, evaluated, err := tracker.expire(now.Add(time.Second / 2))
+	if err != nil || len(resubmitted) != 0 || len(evaluated) != 0 {
+		t.Fatalf("no individual should expire before the timeout, got %v, %v, %v", resubmitted, evaluated, err)
+	}
+
+	resubmitted, _, err = tracker.expire(now.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resubmitted) != 3 || resubmitted[0].Id != 0 || resubmitted[2].Id != 2 {
+		t.Errorf("expected the individuals published again by id, got %v", resubmitted)
+	}
+
+	if _, _, err := tracker.expire(now.Add(2 * time.Second)); err == nil {
+		t.Error("expected an error after the retries")
+	}
+}
+
+func TestEvaluationTrackerLocalPolicy(t *testing.T) {
+	tracker, individuals := newTestTracker(t, 0, localTimeoutPolicy)
+	now := time.Now()
+	tracker.track(individuals, now)
+
+	_, evaluated, err := tracker.expire(now.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evaluated) != 3 || tracker.len() != 0 {
+		t.Fatalf("expected every individual evaluated, got %v", evaluated)
+	}
+	for _, individual := range evaluated {
+		if individual.FitnessValue == nil {
+			t.Errorf("individual %v not evaluated", individual.Id)
+		}
+	}
+}
+
+func TestEvaluationTrackerWorstPolicy(t *testing.T) {
+	tracker, individuals := newTestTracker(t, 0, worstTimeoutPolicy)
+	now := time.Now()
+	tracker.track(individuals, now)
+
+	// The sphere function is minimized.
+	individuals[0].FitnessValue = ga.Float64FitnessValue(5)
+	individuals[1].FitnessValue = ga.Float64FitnessValue(1)
+	tracker.receive(individuals[0])
+	tracker.receive(individuals[1])
+
+	_, evaluated, err := tracker.expire(now.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evaluated) != 1 || evaluated[0].FitnessValue != ga.Float64FitnessValue(5) {
+		t.Errorf("expected the worst fitness value 5, got %v", evaluated)
+	}
+}
+
+func TestNewEvaluationTrackerUnknownPolicy(t *testing.T) {
+	problem, err := ga.NewProblem("sphere", ga.ProblemParameters{ChromosomeSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newEvaluationTracker(time.Second, 0, "ignore", problem); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
@@ -0,0 +1,273 @@
+package transport
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/streadway/amqp"
+
+	"github.com/pasqualesalza/amqpga/communication"
+)
+
+// Minimum time between two publications of the context of an experiment, so
+// that many slaves asking at once receive a single copy.
+const contextInterval = time.Second
+
+// Time between two announcements of an experiment, so that the slaves started
+// later discover it.
+const announcementInterval = 5 * time.Second
+
+// Carries the messages through RabbitMQ. The queues and the exchanges of the
+// node are declared by the setup of the session, which is owned by the caller.
+type AMQPTransport struct {
+	session *communication.Session
+	nodeId  string
+
+	mutex sync.Mutex
+	// Stops announcing and serving the context of the started experiments, by
+	// id.
+	experiments map[string]func()
+}
+
+func NewAMQPTransport(session *communication.Session, nodeId string) *AMQPTransport {
+	return &AMQPTransport{
+		session:     session,
+		nodeId:      nodeId,
+		experiments: make(map[string]func()),
+	}
+}
+
+// Settles a delivery of RabbitMQ.
+type amqpAcknowledger struct {
+	delivery amqp.Delivery
+}
+
+func (acknowledger *amqpAcknowledger) ack() error {
+	return acknowledger.delivery.Ack(false)
+}
+
+func (acknowledger *amqpAcknowledger) nack() error {
+	return acknowledger.delivery.Nack(false, true)
+}
+
+func (acknowledger *amqpAcknowledger) reject(reason error) error {
+	return communication.DeadLetter(acknowledger.delivery, reason)
+}
+
+// Converts the deliveries of RabbitMQ to messages.
+func deliveries(input <-chan amqp.Delivery) <-chan *Message {
+	output := make(chan *Message)
+	go func() {
+		defer close(output)
+		for delivery := range input {
+			message := &Message{
+				ContentType:     delivery.ContentType,
+				ContentEncoding: delivery.ContentEncoding,
+				CorrelationId:   delivery.CorrelationId,
+				ReplyTo:         delivery.ReplyTo,
+				Headers:         delivery.Headers,
+				Body:            delivery.Body,
+			}
+			output <- message.deliver(&amqpAcknowledger{delivery: delivery})
+		}
+	}()
+	return output
+}
+
+// Publishes a message on a queue through the default exchange.
+func (transport *AMQPTransport) publish(queue string, message *Message) error {
+	properties := communication.BatchProperties{
+		ContentType:     message.ContentType,
+		ContentEncoding: message.ContentEncoding,
+		CorrelationId:   message.CorrelationId,
+		ReplyTo:         message.ReplyTo,
+		Headers:         amqp.Table(message.Headers),
+	}
+	return transport.session.Do(func(channel *amqp.Channel) error {
+		return communication.PublishBatch(message.Body, properties, channel, &amqp.Queue{Name: queue})
+	})
+}
+
+// Publishes the context and announces the experiment every announcement
+// interval, until it finishes. An experiment can be started only once.
+func (transport *AMQPTransport) StartExperiment(experiment *Experiment) error {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	if _, ok := transport.experiments[experiment.Id]; ok {
+		return fmt.Errorf("experiment %v already started", experiment.Id)
+	}
+
+	announcement := &communication.Announcement{
+		ExperimentId: experiment.Id,
+		RequestQueue: communication.RequestQueueName(experiment.Id),
+		Problem:      experiment.Problem,
+	}
+	data, err := announcement.Encode()
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan bool)
+	served := make(chan bool)
+	go func() {
+		defer close(served)
+		transport.serveContext(experiment, stop)
+	}()
+
+	stopped := make(chan bool)
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(announcementInterval)
+		defer ticker.Stop()
+		for {
+			err := transport.session.Do(func(channel *amqp.Channel) error {
+				return communication.PublishAnnouncement(data, channel)
+			})
+			if err != nil {
+				log.WithFields(log.Fields{
+					"error": err,
+				}).Warn("Failed to announce the experiment")
+			}
+
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	transport.experiments[experiment.Id] = func() {
+		close(stop)
+		<-stopped
+		<-served
+	}
+	return nil
+}
+
+// Publishes the context of the experiment, and again when a slave asks for it,
+// until the stop channel is closed.
+func (transport *AMQPTransport) serveContext(experiment *Experiment, stop <-chan bool) {
+	var lastPublished time.Time
+	publish := func() {
+		if time.Since(lastPublished) < contextInterval {
+			return
+		}
+		lastPublished = time.Now()
+
+		err := transport.session.Do(func(channel *amqp.Channel) error {
+			return communication.PublishContext(experiment.Context, experiment.ContextHash, channel, experiment.Id)
+		})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Warn("Failed to publish the problem context")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"experiment": experiment.Id,
+			"hash":       experiment.ContextHash,
+			"size":       len(experiment.Context),
+		}).Debug("Published the problem context")
+	}
+
+	publish()
+	for message := range transport.session.ConsumeUntil(&amqp.Queue{Name: communication.ContextQueueName(transport.nodeId)}, stop) {
+		message.Ack(false)
+		publish()
+	}
+}
+
+// Stops announcing the experiment and serving its context, and deletes its
+// request queue, so that the broker cancels the slaves consuming it.
+func (transport *AMQPTransport) FinishExperiment(experimentId string) error {
+	transport.mutex.Lock()
+	stop, ok := transport.experiments[experimentId]
+	delete(transport.experiments, experimentId)
+	transport.mutex.Unlock()
+	if ok {
+		stop()
+	}
+
+	return transport.session.Do(func(channel *amqp.Channel) error {
+		return communication.DeleteRequestQueue(channel, experimentId)
+	})
+}
+
+func (transport *AMQPTransport) PublishTask(experimentId string, task *Message) error {
+	return transport.publish(communication.RequestQueueName(experimentId), task)
+}
+
+func (transport *AMQPTransport) ConsumeResults(experimentId string) (string, <-chan *Message, error) {
+	queue := communication.ReplyQueueName(experimentId, transport.nodeId)
+	return queue, deliveries(transport.session.Consume(&amqp.Queue{Name: queue})), nil
+}
+
+func (transport *AMQPTransport) ConsumeTasks(experimentId string, prefetch int) (<-chan *Message, error) {
+	return deliveries(transport.session.ConsumeWithPrefetch(&amqp.Queue{Name: communication.RequestQueueName(experimentId)}, prefetch)), nil
+}
+
+func (transport *AMQPTransport) PublishResult(replyTo string, result *Message) error {
+	return transport.publish(replyTo, result)
+}
+
+// Consumes the announcements, moving the malformed ones to the dead letter
+// queue.
+func (transport *AMQPTransport) Experiments() (<-chan string, error) {
+	announcementQueue := &amqp.Queue{Name: communication.AnnouncementQueueName(transport.nodeId)}
+	experiments := make(chan string)
+	go func() {
+		defer close(experiments)
+		for message := range transport.session.Consume(announcementQueue) {
+			announcement := new(communication.Announcement)
+			if err := announcement.Decode(message.Body); err != nil {
+				communication.DeadLetter(message, err)
+				continue
+			}
+			message.Ack(false)
+
+			if announcement.RequestQueue != communication.RequestQueueName(announcement.ExperimentId) {
+				log.WithFields(log.Fields{
+					"experiment": announcement.ExperimentId,
+					"queue":      announcement.RequestQueue,
+				}).Warn("Ignored an experiment with an unexpected request queue")
+				continue
+			}
+			experiments <- announcement.ExperimentId
+		}
+	}()
+	return experiments, nil
+}
+
+func (transport *AMQPTransport) Contexts() (<-chan *Message, error) {
+	return deliveries(transport.session.Consume(&amqp.Queue{Name: communication.ContextQueueName(transport.nodeId)})), nil
+}
+
+func (transport *AMQPTransport) RequestContext(experimentId string) error {
+	return transport.session.Do(func(channel *amqp.Channel) error {
+		return communication.PublishContextRequest(channel, experimentId)
+	})
+}
+
+func (transport *AMQPTransport) Reconnections() <-chan bool {
+	return transport.session.Reconnections()
+}
+
+// Stops announcing and serving the context of the experiments still running.
+// The session stays open.
+func (transport *AMQPTransport) Close() error {
+	transport.mutex.Lock()
+	experiments := transport.experiments
+	transport.experiments = make(map[string]func())
+	transport.mutex.Unlock()
+
+	for _, stop := range experiments {
+		stop()
+	}
+	return nil
+}
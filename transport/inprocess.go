@@ -0,0 +1,425 @@
+package transport
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/pasqualesalza/amqpga/communication"
+	"github.com/pasqualesalza/amqpga/config"
+)
+
+// A queue of messages shared by competing consumers.
+type queue struct {
+	mutex    sync.Mutex
+	cond     *sync.Cond
+	messages []*Message
+	closed   bool
+	// Closed with the queue, ending the consumers waiting for a settlement.
+	done chan bool
+}
+
+func newQueue() *queue {
+	queue := &queue{done: make(chan bool)}
+	queue.cond = sync.NewCond(&queue.mutex)
+	return queue
+}
+
+// Appends a message, or puts it back in front when it is given back.
+func (queue *queue) push(message *Message, front bool) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	if queue.closed {
+		return
+	}
+	if front {
+		queue.messages = append([]*Message{message}, queue.messages...)
+	} else {
+		queue.messages = append(queue.messages, message)
+	}
+	queue.cond.Signal()
+}
+
+// Ends the consumers of the queue, dropping its messages.
+func (queue *queue) close() {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	if queue.closed {
+		return
+	}
+	queue.closed = true
+	queue.messages = nil
+	close(queue.done)
+	queue.cond.Broadcast()
+}
+
+// Delivers the messages to a consumer until the queue is closed or the stop
+// channel is closed. As the fair dispatch of RabbitMQ, up to prefetch messages
+// are delivered before being settled, so that a busy consumer leaves the next
+// messages to the others. The deliveries are settled by the acknowledger built
+// for each message, which signals the settlement.
+func (queue *queue) consume(stop <-chan bool, prefetch int, acknowledger func(message *Message, settled chan<- bool) acknowledger) <-chan *Message {
+	if prefetch < 1 {
+		prefetch = 1
+	}
+	output := make(chan *Message)
+	// Never blocks the settlements, since at most prefetch are pending.
+	settled := make(chan bool, prefetch)
+
+	// Wakes up the consumer waiting for a message when it is stopped.
+	stopped := false
+	go func() {
+		<-stop
+		queue.mutex.Lock()
+		stopped = true
+		queue.cond.Broadcast()
+		queue.mutex.Unlock()
+	}()
+
+	go func() {
+		defer close(output)
+		unsettled := 0
+		for {
+			for unsettled >= prefetch {
+				select {
+				case <-settled:
+					unsettled--
+				case <-queue.done:
+					return
+				case <-stop:
+					return
+				}
+			}
+
+			queue.mutex.Lock()
+			for len(queue.messages) == 0 && !queue.closed && !stopped {
+				queue.cond.Wait()
+			}
+			if queue.closed || stopped {
+				queue.mutex.Unlock()
+				return
+			}
+			message := queue.messages[0]
+			queue.messages = queue.messages[1:]
+			queue.mutex.Unlock()
+
+			select {
+			case output <- message.deliver(acknowledger(message, settled)):
+				unsettled++
+			case <-stop:
+				queue.push(message, true)
+				return
+			}
+		}
+	}()
+	return output
+}
+
+// Settles a message of an in-process queue.
+type queueAcknowledger struct {
+	transport *InProcessTransport
+	queue     *queue
+	message   *Message
+	// Wakes up the consumer waiting for the settlement.
+	settled chan<- bool
+	done    int32
+}
+
+// Marks the message as settled, failing if it already is.
+func (acknowledger *queueAcknowledger) settle() error {
+	if !atomic.CompareAndSwapInt32(&acknowledger.done, 0, 1) {
+		return fmt.Errorf("message already settled")
+	}
+	return nil
+}
+
+func (acknowledger *queueAcknowledger) ack() error {
+	if err := acknowledger.settle(); err != nil {
+		return err
+	}
+	acknowledger.settled <- true
+	return nil
+}
+
+func (acknowledger *queueAcknowledger) nack() error {
+	if err := acknowledger.settle(); err != nil {
+		return err
+	}
+	acknowledger.queue.push(acknowledger.message, true)
+	acknowledger.settled <- true
+	return nil
+}
+
+func (acknowledger *queueAcknowledger) reject(reason error) error {
+	if err := acknowledger.settle(); err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{
+		"error":         reason,
+		"correlationId": acknowledger.message.CorrelationId,
+	}).Warn("Rejected a message to the dead letter queue")
+
+	acknowledger.transport.deadLetters.push(acknowledger.message, false)
+	acknowledger.settled <- true
+	return nil
+}
+
+// Carries the messages through channels between the goroutines of a single
+// process, for the tests and for the TCP transport of a master.
+type InProcessTransport struct {
+	mutex  sync.Mutex
+	queues map[string]*queue
+	// The started experiments, and the finished ones mapped to nil.
+	experiments map[string]*Experiment
+	// A queue for each consumer of the experiments and of the contexts.
+	experimentConsumers map[*queue]bool
+	contextConsumers    map[*queue]bool
+	deadLetters         *queue
+	closed              bool
+	// Numbers the result queues.
+	consumers int64
+	stop      chan bool
+}
+
+func NewInProcessTransport() *InProcessTransport {
+	return &InProcessTransport{
+		queues:              make(map[string]*queue),
+		experiments:         make(map[string]*Experiment),
+		experimentConsumers: make(map[*queue]bool),
+		contextConsumers:    make(map[*queue]bool),
+		deadLetters:         newQueue(),
+		stop:                make(chan bool),
+	}
+}
+
+// Returns the queue with the name, creating it if missing. The caller holds the
+// mutex.
+func (transport *InProcessTransport) queue(name string) *queue {
+	queue, ok := transport.queues[name]
+	if !ok {
+		queue = newQueue()
+		transport.queues[name] = queue
+	}
+	return queue
+}
+
+func (transport *InProcessTransport) acknowledger(queue *queue) func(message *Message, settled chan<- bool) acknowledger {
+	return func(message *Message, settled chan<- bool) acknowledger {
+		return &queueAcknowledger{transport: transport, queue: queue, message: message, settled: settled}
+	}
+}
+
+// Returns the message of the context of an experiment.
+func contextMessage(experiment *Experiment) *Message {
+	return &Message{
+		Headers: map[string]interface{}{config.ContextHashHeader: experiment.ContextHash},
+		Body:    experiment.Context,
+	}
+}
+
+func (transport *InProcessTransport) StartExperiment(experiment *Experiment) error {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	if transport.closed {
+		return closedError("start the experiment")
+	}
+	if _, ok := transport.experiments[experiment.Id]; ok {
+		return fmt.Errorf("experiment %v already started", experiment.Id)
+	}
+	transport.experiments[experiment.Id] = experiment
+	transport.queue(communication.RequestQueueName(experiment.Id))
+
+	for consumer := range transport.experimentConsumers {
+		consumer.push(&Message{Body: []byte(experiment.Id)}, false)
+	}
+	for consumer := range transport.contextConsumers {
+		consumer.push(contextMessage(experiment), false)
+	}
+	return nil
+}
+
+// Closes the queue of the tasks, ending its consumers.
+func (transport *InProcessTransport) FinishExperiment(experimentId string) error {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	transport.experiments[experimentId] = nil
+	transport.queue(communication.RequestQueueName(experimentId)).close()
+	return nil
+}
+
+func (transport *InProcessTransport) PublishTask(experimentId string, task *Message) error {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	if transport.closed {
+		return closedError("publish the task")
+	}
+	if experiment, ok := transport.experiments[experimentId]; ok && experiment == nil {
+		return fmt.Errorf("failed to publish the task: experiment %v finished", experimentId)
+	}
+	transport.queue(communication.RequestQueueName(experimentId)).push(task.deliver(nil), false)
+	return nil
+}
+
+func (transport *InProcessTransport) ConsumeResults(experimentId string) (string, <-chan *Message, error) {
+	return transport.consumeResults(experimentId, transport.stop)
+}
+
+// Consumes the results on a new queue until the stop channel is closed.
+func (transport *InProcessTransport) consumeResults(experimentId string, stop <-chan bool) (string, <-chan *Message, error) {
+	name := communication.ReplyQueueName(experimentId, strconv.FormatInt(atomic.AddInt64(&transport.consumers, 1), 10))
+	messages, err := transport.consumeQueue(name, 1, stop)
+	return name, messages, err
+}
+
+func (transport *InProcessTransport) ConsumeTasks(experimentId string, prefetch int) (<-chan *Message, error) {
+	return transport.consumeQueue(communication.RequestQueueName(experimentId), prefetch, transport.stop)
+}
+
+// Consumes a queue with the prefetch until the stop channel is closed.
+func (transport *InProcessTransport) consumeQueue(name string, prefetch int, stop <-chan bool) (<-chan *Message, error) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	if transport.closed {
+		return nil, closedError("consume the queue")
+	}
+	queue := transport.queue(name)
+	return queue.consume(stop, prefetch, transport.acknowledger(queue)), nil
+}
+
+func (transport *InProcessTransport) PublishResult(replyTo string, result *Message) error {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	if transport.closed {
+		return closedError("publish the result")
+	}
+	transport.queue(replyTo).push(result.deliver(nil), false)
+	return nil
+}
+
+// Registers a consumer of the messages sent to everyone, removed when the stop
+// channel is closed. The caller holds the mutex.
+func (transport *InProcessTransport) subscribe(consumers map[*queue]bool, stop <-chan bool) *queue {
+	consumer := newQueue()
+	consumers[consumer] = true
+	go func() {
+		<-stop
+		transport.mutex.Lock()
+		delete(consumers, consumer)
+		transport.mutex.Unlock()
+		consumer.close()
+	}()
+	return consumer
+}
+
+// Consumes the ids of the experiments as messages, receiving the ones already
+// started first.
+func (transport *InProcessTransport) consumeExperiments(stop <-chan bool) (<-chan *Message, error) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	if transport.closed {
+		return nil, closedError("consume the experiments")
+	}
+	consumer := transport.subscribe(transport.experimentConsumers, stop)
+	for id, experiment := range transport.experiments {
+		if experiment != nil {
+			consumer.push(&Message{Body: []byte(id)}, false)
+		}
+	}
+	return consumer.consume(stop, 1, transport.acknowledger(consumer)), nil
+}
+
+func (transport *InProcessTransport) Experiments() (<-chan string, error) {
+	messages, err := transport.consumeExperiments(transport.stop)
+	if err != nil {
+		return nil, err
+	}
+	return experimentIds(messages), nil
+}
+
+// Converts the messages of the experiments to their ids.
+func experimentIds(messages <-chan *Message) <-chan string {
+	experiments := make(chan string)
+	go func() {
+		defer close(experiments)
+		for message := range messages {
+			message.Ack()
+			experiments <- string(message.Body)
+		}
+	}()
+	return experiments
+}
+
+// Consumes the contexts until the stop channel is closed.
+func (transport *InProcessTransport) consumeContexts(stop <-chan bool) (<-chan *Message, error) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	if transport.closed {
+		return nil, closedError("consume the contexts")
+	}
+	consumer := transport.subscribe(transport.contextConsumers, stop)
+	return consumer.consume(stop, 1, transport.acknowledger(consumer)), nil
+}
+
+func (transport *InProcessTransport) Contexts() (<-chan *Message, error) {
+	return transport.consumeContexts(transport.stop)
+}
+
+// Publishes the context again to every consumer.
+func (transport *InProcessTransport) RequestContext(experimentId string) error {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	if transport.closed {
+		return closedError("request the context")
+	}
+	experiment := transport.experiments[experimentId]
+	if experiment == nil {
+		// Nobody serves the experiment, as with a broker.
+		return nil
+	}
+	for consumer := range transport.contextConsumers {
+		consumer.push(contextMessage(experiment), false)
+	}
+	return nil
+}
+
+// The in-process transport never reconnects.
+func (transport *InProcessTransport) Reconnections() <-chan bool {
+	return nil
+}
+
+// Returns the messages rejected so far.
+func (transport *InProcessTransport) DeadLetters() []*Message {
+	transport.deadLetters.mutex.Lock()
+	defer transport.deadLetters.mutex.Unlock()
+
+	return append([]*Message(nil), transport.deadLetters.messages...)
+}
+
+// Ends every consumer.
+func (transport *InProcessTransport) Close() error {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	if transport.closed {
+		return nil
+	}
+	transport.closed = true
+	close(transport.stop)
+	for _, queue := range transport.queues {
+		queue.close()
+	}
+	return nil
+}
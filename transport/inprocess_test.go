@@ -0,0 +1,232 @@
+package transport
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pasqualesalza/amqpga/config"
+)
+
+// Returns the next message of the channel, failing after a second.
+func receive(t *testing.T, messages <-chan *Message) *Message {
+	select {
+	case message, ok := <-messages:
+		if !ok {
+			t.Fatal("channel closed")
+		}
+		return message
+	case <-time.After(time.Second):
+		t.Fatal("no message within a second")
+	}
+	return nil
+}
+
+// Checks that the channel is closed within a second.
+func expectClosed(t *testing.T, messages <-chan *Message) {
+	select {
+	case message, ok := <-messages:
+		if ok {
+			t.Fatalf("unexpected message %v", message.CorrelationId)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed within a second")
+	}
+}
+
+// Checks that no message arrives for a while.
+func expectNothing(t *testing.T, messages <-chan *Message) {
+	select {
+	case message := <-messages:
+		t.Fatalf("unexpected message %v", message)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestInProcessTransportRoundTrip(t *testing.T) {
+	transport := NewInProcessTransport()
+	defer transport.Close()
+
+	if err := transport.StartExperiment(&Experiment{Id: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	replyTo, results, err := transport.ConsumeResults("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := transport.ConsumeTasks("a", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := &Message{CorrelationId: "1", ReplyTo: replyTo, Body: []byte("task")}
+	if err := transport.PublishTask("a", task); err != nil {
+		t.Fatal(err)
+	}
+	received := receive(t, tasks)
+	if received.CorrelationId != "1" || string(received.Body) != "task" || received.ReplyTo != replyTo {
+		t.Fatalf("unexpected task %+v", received)
+	}
+
+	if err := transport.PublishResult(received.ReplyTo, &Message{CorrelationId: "1", Body: []byte("result")}); err != nil {
+		t.Fatal(err)
+	}
+	received.Ack()
+
+	result := receive(t, results)
+	if result.CorrelationId != "1" || string(result.Body) != "result" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestInProcessTransportCompetingConsumers(t *testing.T) {
+	transport := NewInProcessTransport()
+	defer transport.Close()
+
+	first, _ := transport.ConsumeTasks("a", 1)
+	second, _ := transport.ConsumeTasks("a", 1)
+	for i := 0; i < 4; i++ {
+		transport.PublishTask("a", &Message{CorrelationId: fmt.Sprint(i)})
+	}
+
+	// Every task is delivered once.
+	seen := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		var message *Message
+		select {
+		case message = <-first:
+		case message = <-second:
+		case <-time.After(time.Second):
+			t.Fatal("no task within a second")
+		}
+		if seen[message.CorrelationId] {
+			t.Fatalf("task %v delivered twice", message.CorrelationId)
+		}
+		seen[message.CorrelationId] = true
+		message.Ack()
+	}
+}
+
+func TestInProcessTransportNackRequeues(t *testing.T) {
+	transport := NewInProcessTransport()
+	defer transport.Close()
+
+	tasks, _ := transport.ConsumeTasks("a", 1)
+	transport.PublishTask("a", &Message{CorrelationId: "1"})
+	transport.PublishTask("a", &Message{CorrelationId: "2"})
+
+	message := receive(t, tasks)
+	if err := message.Nack(); err != nil {
+		t.Fatal(err)
+	}
+	if err := message.Ack(); err == nil {
+		t.Fatal("settled twice")
+	}
+
+	// The task given back is delivered again before the others.
+	if again := receive(t, tasks); again.CorrelationId != message.CorrelationId {
+		t.Fatalf("expected task %v, got %v", message.CorrelationId, again.CorrelationId)
+	}
+}
+
+func TestInProcessTransportPrefetch(t *testing.T) {
+	transport := NewInProcessTransport()
+	defer transport.Close()
+
+	tasks, _ := transport.ConsumeTasks("a", 2)
+	for i := 0; i < 3; i++ {
+		transport.PublishTask("a", &Message{CorrelationId: fmt.Sprint(i)})
+	}
+
+	// Two tasks are delivered before being settled, the third one after.
+	first := receive(t, tasks)
+	receive(t, tasks)
+	expectNothing(t, tasks)
+	first.Ack()
+	if message := receive(t, tasks); message.CorrelationId != "2" {
+		t.Fatalf("unexpected task %v", message.CorrelationId)
+	}
+}
+
+func TestInProcessTransportRejectDeadLetters(t *testing.T) {
+	transport := NewInProcessTransport()
+	defer transport.Close()
+
+	tasks, _ := transport.ConsumeTasks("a", 1)
+	transport.PublishTask("a", &Message{CorrelationId: "1"})
+
+	receive(t, tasks).Reject(fmt.Errorf("malformed"))
+	expectNothing(t, tasks)
+
+	deadLetters := transport.DeadLetters()
+	if len(deadLetters) != 1 || deadLetters[0].CorrelationId != "1" {
+		t.Fatalf("unexpected dead letters %v", deadLetters)
+	}
+}
+
+func TestInProcessTransportFinishExperiment(t *testing.T) {
+	transport := NewInProcessTransport()
+	defer transport.Close()
+
+	transport.StartExperiment(&Experiment{Id: "a"})
+	tasks, _ := transport.ConsumeTasks("a", 1)
+	if err := transport.FinishExperiment("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectClosed(t, tasks)
+	if err := transport.PublishTask("a", &Message{}); err == nil {
+		t.Fatal("published a task of a finished experiment")
+	}
+}
+
+func TestInProcessTransportExperimentsAndContexts(t *testing.T) {
+	transport := NewInProcessTransport()
+	defer transport.Close()
+
+	transport.StartExperiment(&Experiment{Id: "a", Context: []byte("a context"), ContextHash: "ha"})
+
+	// The experiments already started are received first.
+	experiments, _ := transport.Experiments()
+	contexts, _ := transport.Contexts()
+	transport.StartExperiment(&Experiment{Id: "b", Context: []byte("b context"), ContextHash: "hb"})
+
+	for _, expected := range []string{"a", "b"} {
+		select {
+		case id := <-experiments:
+			if id != expected {
+				t.Fatalf("expected experiment %v, got %v", expected, id)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no experiment within a second")
+		}
+	}
+
+	// The contexts published before consuming them are requested again.
+	context := receive(t, contexts)
+	if string(context.Body) != "b context" {
+		t.Fatalf("unexpected context %q", context.Body)
+	}
+	context.Ack()
+	if err := transport.RequestContext("a"); err != nil {
+		t.Fatal(err)
+	}
+	context = receive(t, contexts)
+	if string(context.Body) != "a context" || context.Headers[config.ContextHashHeader] != "ha" {
+		t.Fatalf("unexpected context %+v", context)
+	}
+}
+
+func TestInProcessTransportClose(t *testing.T) {
+	transport := NewInProcessTransport()
+
+	tasks, _ := transport.ConsumeTasks("a", 1)
+	_, results, _ := transport.ConsumeResults("a")
+	transport.Close()
+
+	expectClosed(t, tasks)
+	expectClosed(t, results)
+	if err := transport.PublishTask("a", &Message{}); err == nil {
+		t.Fatal("published on a closed transport")
+	}
+}
@@ -0,0 +1,516 @@
+package transport
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/pasqualesalza/amqpga/communication"
+)
+
+// The operations of the frames exchanged by the TCP transports. The clients
+// send the requests and settle the deliveries, the server replies and delivers
+// the messages of the consumers.
+const (
+	startExperimentOperation    = "startExperiment"
+	finishExperimentOperation   = "finishExperiment"
+	publishTaskOperation        = "publishTask"
+	consumeResultsOperation     = "consumeResults"
+	consumeTasksOperation       = "consumeTasks"
+	publishResultOperation      = "publishResult"
+	consumeExperimentsOperation = "consumeExperiments"
+	consumeContextsOperation    = "consumeContexts"
+	requestContextOperation     = "requestContext"
+	ackOperation                = "ack"
+	nackOperation               = "nack"
+	rejectOperation             = "reject"
+	replyOperation              = "reply"
+	deliverOperation            = "deliver"
+	cancelOperation             = "cancel"
+)
+
+// The number of messages a TCP consumer receives before settling them, unless
+// it consumes the tasks with another prefetch.
+const tcpPrefetch = 1
+
+// A frame exchanged by the TCP transports, encoded with gob.
+type frame struct {
+	Operation string
+	// Pairs a reply with its request.
+	Request uint64
+	// The consumer of a delivery, chosen by the client.
+	Consumer uint64
+	// Identifies a delivery to settle.
+	Tag uint64
+	// The deliveries a consumer of the tasks receives before settling them.
+	Prefetch     int
+	ExperimentId string
+	Queue        string
+	Message      *Message
+	Experiment   *Experiment
+	// The error of a reply or the reason of a rejection.
+	Error string
+}
+
+// Serves the queues of a master to the slaves connecting through TCP, without
+// a broker. The master uses the embedded in-process transport.
+type TCPServerTransport struct {
+	*InProcessTransport
+	listener net.Listener
+
+	mutex       sync.Mutex
+	connections map[net.Conn]bool
+}
+
+// Listens on the address, such as ":5673".
+func ListenTCP(address string) (*TCPServerTransport, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %v: %v", address, err)
+	}
+
+	server := &TCPServerTransport{
+		InProcessTransport: NewInProcessTransport(),
+		listener:           listener,
+		connections:        make(map[net.Conn]bool),
+	}
+	go server.serve()
+
+	log.WithFields(log.Fields{
+		"address": listener.Addr(),
+	}).Infof("Listening for the slaves on %v", listener.Addr())
+
+	return server, nil
+}
+
+// Returns the address the server listens on.
+func (server *TCPServerTransport) Addr() net.Addr {
+	return server.listener.Addr()
+}
+
+func (server *TCPServerTransport) serve() {
+	for {
+		conn, err := server.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		server.mutex.Lock()
+		server.connections[conn] = true
+		server.mutex.Unlock()
+
+		go server.handle(conn)
+	}
+}
+
+// A connection of a client to the server.
+type tcpConnection struct {
+	server *TCPServerTransport
+	conn   net.Conn
+	// Closed when the connection ends, stopping its consumers.
+	done chan bool
+
+	writeMutex sync.Mutex
+	encoder    *gob.Encoder
+
+	mutex   sync.Mutex
+	nextTag uint64
+	// The deliveries not settled yet, given back when the connection ends.
+	unsettled map[uint64]*Message
+	closed    bool
+}
+
+// Serves the requests of a client until it disconnects.
+func (server *TCPServerTransport) handle(conn net.Conn) {
+	connection := &tcpConnection{
+		server:    server,
+		conn:      conn,
+		done:      make(chan bool),
+		encoder:   gob.NewEncoder(conn),
+		unsettled: make(map[uint64]*Message),
+	}
+
+	log.WithFields(log.Fields{
+		"address": conn.RemoteAddr(),
+	}).Infof("Slave connected from %v", conn.RemoteAddr())
+
+	defer func() {
+		close(connection.done)
+		conn.Close()
+
+		server.mutex.Lock()
+		delete(server.connections, conn)
+		server.mutex.Unlock()
+
+		// The messages of a disconnected slave go to the others.
+		connection.mutex.Lock()
+		connection.closed = true
+		for tag, message := range connection.unsettled {
+			message.Nack()
+			delete(connection.unsettled, tag)
+		}
+		connection.mutex.Unlock()
+
+		log.WithFields(log.Fields{
+			"address": conn.RemoteAddr(),
+		}).Infof("Slave disconnected from %v", conn.RemoteAddr())
+	}()
+
+	decoder := gob.NewDecoder(conn)
+	for {
+		var request frame
+		if err := decoder.Decode(&request); err != nil {
+			return
+		}
+
+		var err error
+		reply := frame{Operation: replyOperation, Request: request.Request}
+		switch request.Operation {
+		case startExperimentOperation:
+			err = server.StartExperiment(request.Experiment)
+		case finishExperimentOperation:
+			err = server.FinishExperiment(request.ExperimentId)
+		case publishTaskOperation:
+			err = server.PublishTask(request.ExperimentId, request.Message)
+		case publishResultOperation:
+			err = server.PublishResult(request.Queue, request.Message)
+		case requestContextOperation:
+			err = server.RequestContext(request.ExperimentId)
+		case consumeResultsOperation:
+			var messages <-chan *Message
+			reply.Queue, messages, err = server.consumeResults(request.ExperimentId, connection.done)
+			connection.forward(request, messages, err)
+		case consumeTasksOperation:
+			messages, consumeErr := server.consumeQueue(communication.RequestQueueName(request.ExperimentId), request.Prefetch, connection.done)
+			err = consumeErr
+			connection.forward(request, messages, err)
+		case consumeExperimentsOperation:
+			messages, consumeErr := server.consumeExperiments(connection.done)
+			err = consumeErr
+			connection.forward(request, messages, err)
+		case consumeContextsOperation:
+			messages, consumeErr := server.consumeContexts(connection.done)
+			err = consumeErr
+			connection.forward(request, messages, err)
+		case ackOperation, nackOperation, rejectOperation:
+			// The settlements have no reply.
+			connection.settle(request)
+			continue
+		default:
+			err = fmt.Errorf("unknown operation %q", request.Operation)
+		}
+
+		if err != nil {
+			reply.Error = err.Error()
+		}
+		if err := connection.send(reply); err != nil {
+			return
+		}
+	}
+}
+
+func (connection *tcpConnection) send(message frame) error {
+	connection.writeMutex.Lock()
+	defer connection.writeMutex.Unlock()
+
+	return connection.encoder.Encode(message)
+}
+
+// Delivers the messages of a consumer to the client, until the consumer ends
+// or the connection closes. The consumer waits for the client to settle the
+// messages beyond its prefetch before delivering the next one.
+func (connection *tcpConnection) forward(request frame, messages <-chan *Message, err error) {
+	if err != nil {
+		return
+	}
+
+	go func() {
+		for {
+			select {
+			case message, ok := <-messages:
+				if !ok {
+					connection.send(frame{Operation: cancelOperation, Consumer: request.Consumer})
+					return
+				}
+
+				connection.mutex.Lock()
+				if connection.closed {
+					// Gives back a message consumed while the connection ended.
+					connection.mutex.Unlock()
+					message.Nack()
+					return
+				}
+				connection.nextTag++
+				tag := connection.nextTag
+				connection.unsettled[tag] = message
+				connection.mutex.Unlock()
+
+				delivery := frame{Operation: deliverOperation, Consumer: request.Consumer, Tag: tag, Message: message}
+				if err := connection.send(delivery); err != nil {
+					return
+				}
+			case <-connection.done:
+				return
+			}
+		}
+	}()
+}
+
+// Settles a delivery as the client asks.
+func (connection *tcpConnection) settle(request frame) {
+	connection.mutex.Lock()
+	message, ok := connection.unsettled[request.Tag]
+	delete(connection.unsettled, request.Tag)
+	connection.mutex.Unlock()
+	if !ok {
+		return
+	}
+
+	switch request.Operation {
+	case ackOperation:
+		message.Ack()
+	case nackOperation:
+		message.Nack()
+	case rejectOperation:
+		message.Reject(errors.New(request.Error))
+	}
+}
+
+// Stops listening, disconnects the slaves and ends the local consumers.
+func (server *TCPServerTransport) Close() error {
+	err := server.listener.Close()
+
+	server.mutex.Lock()
+	for conn := range server.connections {
+		conn.Close()
+	}
+	server.mutex.Unlock()
+
+	server.InProcessTransport.Close()
+	return err
+}
+
+// Carries the messages of a slave through a TCP connection to the server of a
+// master. A lost connection ends the consumers, without reconnecting.
+type TCPClientTransport struct {
+	conn net.Conn
+
+	writeMutex sync.Mutex
+	encoder    *gob.Encoder
+
+	mutex     sync.Mutex
+	nextId    uint64
+	requests  map[uint64]chan frame
+	consumers map[uint64]chan *Message
+	closed    bool
+}
+
+// Connects to the server listening on the address.
+func DialTCP(address string) (*TCPClientTransport, error) {
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %v: %v", address, err)
+	}
+
+	client := &TCPClientTransport{
+		conn:      conn,
+		encoder:   gob.NewEncoder(conn),
+		requests:  make(map[uint64]chan frame),
+		consumers: make(map[uint64]chan *Message),
+	}
+	go client.receive()
+
+	log.WithFields(log.Fields{
+		"address": address,
+	}).Infof("Connected to the master on %v", address)
+
+	return client, nil
+}
+
+// Dispatches the replies and the deliveries of the server, until the
+// connection closes.
+func (client *TCPClientTransport) receive() {
+	decoder := gob.NewDecoder(client.conn)
+	for {
+		var message frame
+		if err := decoder.Decode(&message); err != nil {
+			break
+		}
+
+		client.mutex.Lock()
+		switch message.Operation {
+		case replyOperation:
+			if reply, ok := client.requests[message.Request]; ok {
+				reply <- message
+				delete(client.requests, message.Request)
+			}
+		case deliverOperation:
+			// Never blocks, since the server respects the prefetch.
+			if consumer, ok := client.consumers[message.Consumer]; ok {
+				consumer <- message.Message.deliver(&tcpAcknowledger{client: client, tag: message.Tag})
+			}
+		case cancelOperation:
+			if consumer, ok := client.consumers[message.Consumer]; ok {
+				close(consumer)
+				delete(client.consumers, message.Consumer)
+			}
+		}
+		client.mutex.Unlock()
+	}
+
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	client.closed = true
+	for id, reply := range client.requests {
+		reply <- frame{Operation: replyOperation, Request: id, Error: "connection closed"}
+		delete(client.requests, id)
+	}
+	for id, consumer := range client.consumers {
+		close(consumer)
+		delete(client.consumers, id)
+	}
+}
+
+func (client *TCPClientTransport) send(message frame) error {
+	client.writeMutex.Lock()
+	defer client.writeMutex.Unlock()
+
+	return client.encoder.Encode(message)
+}
+
+// Sends a request and waits for its reply.
+func (client *TCPClientTransport) request(request frame) (frame, error) {
+	client.mutex.Lock()
+	if client.closed {
+		client.mutex.Unlock()
+		return frame{}, closedError(request.Operation)
+	}
+	client.nextId++
+	request.Request = client.nextId
+	reply := make(chan frame, 1)
+	client.requests[request.Request] = reply
+	client.mutex.Unlock()
+
+	if err := client.send(request); err != nil {
+		client.mutex.Lock()
+		delete(client.requests, request.Request)
+		client.mutex.Unlock()
+		return frame{}, fmt.Errorf("failed to %v: %v", request.Operation, err)
+	}
+
+	response := <-reply
+	if response.Error != "" {
+		return response, fmt.Errorf("failed to %v: %v", request.Operation, response.Error)
+	}
+	return response, nil
+}
+
+// Registers a consumer and asks the server to deliver it the messages.
+func (client *TCPClientTransport) consume(request frame) (frame, <-chan *Message, error) {
+	if request.Prefetch < tcpPrefetch {
+		request.Prefetch = tcpPrefetch
+	}
+
+	client.mutex.Lock()
+	client.nextId++
+	request.Consumer = client.nextId
+	consumer := make(chan *Message, request.Prefetch)
+	client.consumers[request.Consumer] = consumer
+	client.mutex.Unlock()
+
+	reply, err := client.request(request)
+	if err != nil {
+		client.mutex.Lock()
+		if _, ok := client.consumers[request.Consumer]; ok {
+			delete(client.consumers, request.Consumer)
+			close(consumer)
+		}
+		client.mutex.Unlock()
+		return reply, nil, err
+	}
+	return reply, consumer, nil
+}
+
+// Settles a delivery through the server.
+type tcpAcknowledger struct {
+	client *TCPClientTransport
+	tag    uint64
+}
+
+func (acknowledger *tcpAcknowledger) ack() error {
+	return acknowledger.client.send(frame{Operation: ackOperation, Tag: acknowledger.tag})
+}
+
+func (acknowledger *tcpAcknowledger) nack() error {
+	return acknowledger.client.send(frame{Operation: nackOperation, Tag: acknowledger.tag})
+}
+
+func (acknowledger *tcpAcknowledger) reject(reason error) error {
+	log.WithFields(log.Fields{
+		"error": reason,
+	}).Warn("Rejected a message to the dead letter queue")
+
+	return acknowledger.client.send(frame{Operation: rejectOperation, Tag: acknowledger.tag, Error: reason.Error()})
+}
+
+func (client *TCPClientTransport) StartExperiment(experiment *Experiment) error {
+	_, err := client.request(frame{Operation: startExperimentOperation, Experiment: experiment})
+	return err
+}
+
+func (client *TCPClientTransport) FinishExperiment(experimentId string) error {
+	_, err := client.request(frame{Operation: finishExperimentOperation, ExperimentId: experimentId})
+	return err
+}
+
+func (client *TCPClientTransport) PublishTask(experimentId string, task *Message) error {
+	_, err := client.request(frame{Operation: publishTaskOperation, ExperimentId: experimentId, Message: task})
+	return err
+}
+
+func (client *TCPClientTransport) ConsumeResults(experimentId string) (string, <-chan *Message, error) {
+	reply, messages, err := client.consume(frame{Operation: consumeResultsOperation, ExperimentId: experimentId})
+	return reply.Queue, messages, err
+}
+
+func (client *TCPClientTransport) ConsumeTasks(experimentId string, prefetch int) (<-chan *Message, error) {
+	_, messages, err := client.consume(frame{Operation: consumeTasksOperation, ExperimentId: experimentId, Prefetch: prefetch})
+	return messages, err
+}
+
+func (client *TCPClientTransport) PublishResult(replyTo string, result *Message) error {
+	_, err := client.request(frame{Operation: publishResultOperation, Queue: replyTo, Message: result})
+	return err
+}
+
+func (client *TCPClientTransport) Experiments() (<-chan string, error) {
+	_, messages, err := client.consume(frame{Operation: consumeExperimentsOperation})
+	if err != nil {
+		return nil, err
+	}
+	return experimentIds(messages), nil
+}
+
+func (client *TCPClientTransport) Contexts() (<-chan *Message, error) {
+	_, messages, err := client.consume(frame{Operation: consumeContextsOperation})
+	return messages, err
+}
+
+func (client *TCPClientTransport) RequestContext(experimentId string) error {
+	_, err := client.request(frame{Operation: requestContextOperation, ExperimentId: experimentId})
+	return err
+}
+
+// The TCP transport never reconnects.
+func (client *TCPClientTransport) Reconnections() <-chan bool {
+	return nil
+}
+
+func (client *TCPClientTransport) Close() error {
+	return client.conn.Close()
+}
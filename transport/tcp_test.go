@@ -0,0 +1,183 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var errMalformed = errors.New("malformed")
+
+// Starts a server on a free port and connects a client to it.
+func startTCP(t *testing.T) (*TCPServerTransport, *TCPClientTransport) {
+	server, err := ListenTCP("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := DialTCP(server.Addr().String())
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return server, client
+}
+
+func TestTCPTransportRoundTrip(t *testing.T) {
+	server, client := startTCP(t)
+	defer server.Close()
+	defer client.Close()
+
+	experiment := &Experiment{Id: "a", Context: []byte("context"), ContextHash: "h"}
+	if err := server.StartExperiment(experiment); err != nil {
+		t.Fatal(err)
+	}
+	replyTo, results, err := server.ConsumeResults("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	experiments, err := client.Experiments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case id := <-experiments:
+		if id != "a" {
+			t.Fatalf("unexpected experiment %v", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no experiment within a second")
+	}
+	tasks, err := client.ConsumeTasks("a", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := &Message{
+		ContentType:   "application/json",
+		CorrelationId: "1",
+		ReplyTo:       replyTo,
+		Headers:       map[string]interface{}{"generation": int64(3), "experimentId": "a"},
+		Body:          []byte("task"),
+	}
+	if err := server.PublishTask("a", task); err != nil {
+		t.Fatal(err)
+	}
+	received := receive(t, tasks)
+	if received.ContentType != task.ContentType || received.CorrelationId != "1" || received.ReplyTo != replyTo ||
+		received.Headers["generation"] != int64(3) || string(received.Body) != "task" {
+		t.Fatalf("unexpected task %+v", received)
+	}
+
+	if err := client.PublishResult(received.ReplyTo, &Message{CorrelationId: "1", Body: []byte("result")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := received.Ack(); err != nil {
+		t.Fatal(err)
+	}
+	if result := receive(t, results); result.CorrelationId != "1" || string(result.Body) != "result" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestTCPTransportPrefetch(t *testing.T) {
+	server, first := startTCP(t)
+	defer server.Close()
+	defer first.Close()
+	second, err := DialTCP(server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	firstTasks, _ := first.ConsumeTasks("a", 1)
+	server.PublishTask("a", &Message{CorrelationId: "1"})
+	receive(t, firstTasks)
+
+	// The first slave is busy, so the next task goes to the second one.
+	secondTasks, _ := second.ConsumeTasks("a", 1)
+	server.PublishTask("a", &Message{CorrelationId: "2"})
+	if message := receive(t, secondTasks); message.CorrelationId != "2" {
+		t.Fatalf("unexpected task %v", message.CorrelationId)
+	}
+	expectNothing(t, firstTasks)
+}
+
+func TestTCPTransportPrefetchOfTheWorkers(t *testing.T) {
+	server, client := startTCP(t)
+	defer server.Close()
+	defer client.Close()
+
+	tasks, _ := client.ConsumeTasks("a", 2)
+	for i := 0; i < 3; i++ {
+		server.PublishTask("a", &Message{CorrelationId: fmt.Sprint(i)})
+	}
+
+	first := receive(t, tasks)
+	receive(t, tasks)
+	expectNothing(t, tasks)
+	first.Ack()
+	if message := receive(t, tasks); message.CorrelationId != "2" {
+		t.Fatalf("unexpected task %v", message.CorrelationId)
+	}
+}
+
+func TestTCPTransportDisconnectRequeues(t *testing.T) {
+	server, first := startTCP(t)
+	defer server.Close()
+
+	firstTasks, _ := first.ConsumeTasks("a", 1)
+	server.PublishTask("a", &Message{CorrelationId: "1"})
+	receive(t, firstTasks)
+
+	// The task of the slave dying before settling it goes to another slave.
+	first.Close()
+	second, err := DialTCP(server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+	secondTasks, _ := second.ConsumeTasks("a", 1)
+	if message := receive(t, secondTasks); message.CorrelationId != "1" {
+		t.Fatalf("unexpected task %v", message.CorrelationId)
+	}
+}
+
+func TestTCPTransportRejectAndFinish(t *testing.T) {
+	server, client := startTCP(t)
+	defer server.Close()
+	defer client.Close()
+
+	server.StartExperiment(&Experiment{Id: "a"})
+	tasks, _ := client.ConsumeTasks("a", 1)
+	server.PublishTask("a", &Message{CorrelationId: "1"})
+	receive(t, tasks).Reject(errMalformed)
+
+	deadline := time.Now().Add(time.Second)
+	for len(server.DeadLetters()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no dead letter within a second")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Finishing the experiment cancels its consumers.
+	if err := server.FinishExperiment("a"); err != nil {
+		t.Fatal(err)
+	}
+	expectClosed(t, tasks)
+}
+
+func TestTCPTransportServerClose(t *testing.T) {
+	server, client := startTCP(t)
+	defer client.Close()
+
+	tasks, _ := client.ConsumeTasks("a", 1)
+	server.Close()
+
+	expectClosed(t, tasks)
+	if err := client.PublishTask("a", &Message{}); err == nil {
+		t.Fatal("published through a closed connection")
+	}
+}
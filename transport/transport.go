@@ -0,0 +1,120 @@
+// Carries the messages of the master/slave model, over RabbitMQ, in-process
+// channels or plain TCP.
+package transport
+
+import (
+	"fmt"
+)
+
+// A message carrying a batch of individuals or the context of an experiment.
+type Message struct {
+	ContentType     string
+	ContentEncoding string
+	// Pairs a result with its task.
+	CorrelationId string
+	// The queue of the result of a task.
+	ReplyTo string
+	Headers map[string]interface{}
+	Body    []byte
+
+	// Settles a consumed message, nil for a published one.
+	acknowledger acknowledger
+}
+
+// Settles a consumed message with the transport delivering it.
+type acknowledger interface {
+	ack() error
+	nack() error
+	reject(reason error) error
+}
+
+// Tells the transport that the message is processed.
+func (message *Message) Ack() error {
+	if message.acknowledger == nil {
+		return nil
+	}
+	return message.acknowledger.ack()
+}
+
+// Gives the message back to the transport, for another consumer.
+func (message *Message) Nack() error {
+	if message.acknowledger == nil {
+		return nil
+	}
+	return message.acknowledger.nack()
+}
+
+// Refuses a malformed message, which is not delivered again.
+func (message *Message) Reject(reason error) error {
+	if message.acknowledger == nil {
+		return nil
+	}
+	return message.acknowledger.reject(reason)
+}
+
+// Returns a copy of the message, settled by the acknowledger.
+func (message *Message) deliver(acknowledger acknowledger) *Message {
+	delivery := *message
+	delivery.acknowledger = acknowledger
+	return &delivery
+}
+
+// An experiment served by a master.
+type Experiment struct {
+	Id string
+	// The JSON descriptor of the problem, for the slaves not written in Go.
+	Problem []byte
+	// The encoded problem context and its hash.
+	Context     []byte
+	ContextHash string
+}
+
+// Carries the tasks of the experiments from their masters to the slaves, the
+// results back and the problem contexts.
+type Transport interface {
+	// Makes the experiment known to the slaves, publishing its context and
+	// again when a slave asks for it.
+	StartExperiment(experiment *Experiment) error
+
+	// Ends the experiment, so that the slaves stop consuming its tasks.
+	FinishExperiment(experimentId string) error
+
+	// Sends a task of the experiment to the slaves.
+	PublishTask(experimentId string, task *Message) error
+
+	// Consumes the results of the experiment sent to this node, returning the
+	// queue the tasks have to reply to.
+	ConsumeResults(experimentId string) (string, <-chan *Message, error)
+
+	// Consumes the tasks of the experiment, until it finishes. Up to prefetch
+	// tasks are delivered before being settled, so that a slave evaluates them
+	// at the same time.
+	ConsumeTasks(experimentId string, prefetch int) (<-chan *Message, error)
+
+	// Sends the result of a task to the queue it replies to.
+	PublishResult(replyTo string, result *Message) error
+
+	// Consumes the ids of the experiments started by the masters. An
+	// experiment may be received more than once.
+	Experiments() (<-chan string, error)
+
+	// Consumes the contexts published by the masters.
+	Contexts() (<-chan *Message, error)
+
+	// Asks the master of the experiment to publish its context again.
+	RequestContext(experimentId string) error
+
+	// Receives a value after each reconnection, when the messages in flight may
+	// have been lost. Nil if the transport never reconnects.
+	Reconnections() <-chan bool
+
+	Close() error
+}
+
+// The names of the transports selectable by flag.
+var Names = []string{"amqp", "tcp"}
+
+// Returns the error of an operation on a closed transport.
+func closedError(operation string) error {
+	return fmt.Errorf("failed to %v: transport closed", operation)
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"fmt"
 	"math"
+	"math/bits"
 	"math/rand"
 	"time"
 
@@ -10,28 +11,28 @@ import (
 )
 
 // Computes the int between [min, max]
-func RandomByteInRange(min, max byte) byte {
-	return byte(RandomIntInRange(int(min), int(max)))
+func RandomByteInRange(random *rand.Rand, min, max byte) byte {
+	return byte(RandomIntInRange(random, int(min), int(max)))
 }
 
 // Computes the int between [min, max]
-func RandomIntInRange(min, max int) int {
-	return rand.Intn(max-min+1) + min
+func RandomIntInRange(random *rand.Rand, min, max int) int {
+	return random.Intn(max-min+1) + min
 }
 
 // Computes the int64 between [min, max]
-func RandomInt64InRange(min, max int64) int64 {
-	return rand.Int63n(max-min+1) + min
+func RandomInt64InRange(random *rand.Rand, min, max int64) int64 {
+	return random.Int63n(max-min+1) + min
 }
 
 // Computes the float32 between [min, max)
-func RandomFloat32InRange(min, max float32) float32 {
-	return float32(RandomFloat64InRange(float64(min), float64(max)))
+func RandomFloat32InRange(random *rand.Rand, min, max float32) float32 {
+	return float32(RandomFloat64InRange(random, float64(min), float64(max)))
 }
 
 // Computes the float64 between [min, max)
-func RandomFloat64InRange(min, max float64) float64 {
-	return rand.Float64()*(max-min) + min
+func RandomFloat64InRange(random *rand.Rand, min, max float64) float64 {
+	return random.Float64()*(max-min) + min
 }
 
 func Hamming(x, y []byte) int {
@@ -45,6 +46,16 @@ func Hamming(x, y []byte) int {
 	return distance
 }
 
+// Computes the Hamming distance of two bit vectors packed in 64-bit words.
+func BitHamming(x, y []uint64) int {
+	distance := 0
+	for i := 0; i < len(x); i++ {
+		distance += bits.OnesCount64(x[i] ^ y[i])
+	}
+
+	return distance
+}
+
 func EuclideanDistance(a, b [2]int) int {
 	xd := float64(a[0] - b[0])
 	yd := float64(a[1] - b[1])